@@ -16,15 +16,22 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/matthewgall/streamup/pkg/streamup"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
@@ -46,14 +53,18 @@ func init() {
 
 var (
 	// S3 Configuration
-	accessKeyID     string
-	secretAccessKey string
-	bucket          string
+	accessKeyID           string
+	secretAccessKey       string
+	sessionToken          string
+	profile               string
+	useDefaultCredentials bool
+	bucket                string
 
 	// Service Configuration
-	accountID string
-	endpoint  string
-	region    string
+	accountID       string
+	endpoint        string
+	region          string
+	addressingStyle string
 
 	// Input Configuration
 	stdinSize int64
@@ -65,12 +76,24 @@ var (
 	minPartSize int64
 	maxPartSize int64
 	maxParts    int
+	adaptive    bool
+	minWorkers  int
+
+	singlePartThreshold int64
+	limitRate           string // Bandwidth limit, e.g. "200M"
 
 	// Retry Configuration
 	maxRetries      int
 	retryDelay      int
 	maxRetryDelay   int
 	retryMultiplier int
+	retryBudget     int
+	requestTimeout  time.Duration
+	abortTimeout    time.Duration
+	partTimeout     time.Duration
+	hedgeMultiplier float64
+	sourceTimeout   time.Duration
+	strictSize      bool
 
 	// Object Metadata
 	contentType        string
@@ -80,14 +103,58 @@ var (
 	cacheControl       string
 	metadata           []string // Key=value pairs
 
+	// Conditional Writes
+	noOverwrite bool
+	ifMatch     string
+
+	// Object Management
+	tags         []string // Key=value pairs
+	storageClass string
+	acl          string
+
 	// Checksum
 	calculateChecksum bool
 	checksumAlgorithm string
 
+	// Server-side Checksum
+	serverChecksum       string
+	serverChecksumType   string
+	sendContentMD5       bool
+	verifyServerChecksum bool
+
+	// Verification
+	verifyUpload bool
+
+	// Compression
+	compress   string
+	decompress bool
+
+	// Server-side Encryption
+	sse           string
+	sseKMSKeyID   string
+	sseKMSContext []string // Key=value pairs
+	sseCKeyFile   string
+
+	// Client-side Encryption
+	encryptionKeyFile string
+	encryptionKeyEnv  string
+
+	// Resume Configuration
+	resume bool
+
 	// Output Configuration
 	quiet bool
+
+	// Logging
+	logLevel  string
+	logFormat string
+	debugHTTP bool
 )
 
+// exitPreconditionFailed is the exit status when a conditional upload
+// (--no-overwrite, --if-match) is rejected.
+const exitPreconditionFailed = 3
+
 var rootCmd = &cobra.Command{
 	Use:   "streamup",
 	Short: "Stream large files to S3-compatible storage",
@@ -99,6 +166,7 @@ Works with Cloudflare R2, AWS S3, Backblaze B2, MinIO, and any S3-compatible ser
 Environment Variables:
   S3_ACCESS_KEY_ID      S3 access key ID
   S3_SECRET_ACCESS_KEY  S3 secret access key
+  S3_SESSION_TOKEN      S3 session token (temporary credentials)
   S3_BUCKET             S3 bucket name
   S3_ENDPOINT           Custom S3 endpoint
   S3_REGION             S3 region
@@ -112,6 +180,9 @@ Environment Variables:
 		if secretAccessKey == "" {
 			secretAccessKey = os.Getenv("S3_SECRET_ACCESS_KEY")
 		}
+		if sessionToken == "" {
+			sessionToken = os.Getenv("S3_SESSION_TOKEN")
+		}
 		if bucket == "" {
 			bucket = os.Getenv("S3_BUCKET")
 		}
@@ -136,7 +207,7 @@ The <key> argument specifies the object key (path) in the bucket.
 The <source> argument can be:
   - A local file path (e.g., /path/to/file.dat)
   - A URL to download and stream (e.g., https://example.com/file.dat)
-  - A dash "-" to read from stdin (requires --size flag)
+  - A dash "-" to read from stdin (--size is optional; part sizes grow as needed)
 
 Examples:
   # Upload a local file to Cloudflare R2
@@ -151,8 +222,14 @@ Examples:
   # Upload from stdin with known size
   pg_dump mydb | gzip | streamup upload backups/db.sql.gz - --size 5000000000
 
+  # Upload from stdin when the size is unknown
+  pg_dump mydb | gzip | streamup upload backups/db.sql.gz -
+
   # Memory-constrained upload
-  streamup upload large.dat /data/large.dat --max-memory 1024`,
+  streamup upload large.dat /data/large.dat --max-memory 1024
+
+  # Resumable upload (re-run the same command to continue after a failure)
+  streamup upload backups/huge.tar /data/huge.tar --resume`,
 	Args: cobra.ExactArgs(2),
 	RunE: runUpload,
 }
@@ -335,29 +412,49 @@ func init() {
 	// Note: We don't set defaults from env vars here to avoid exposing secrets in --help
 	rootCmd.PersistentFlags().StringVar(&accessKeyID, "access-key", "", "S3 access key ID")
 	rootCmd.PersistentFlags().StringVar(&secretAccessKey, "secret-key", "", "S3 secret access key")
+	rootCmd.PersistentFlags().StringVar(&sessionToken, "session-token", "", "S3 session token for temporary credentials")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Use credentials from a named AWS shared config profile")
+	rootCmd.PersistentFlags().BoolVar(&useDefaultCredentials, "use-default-credentials", false, "Use the AWS default credential chain (env, shared config, SSO, instance roles)")
 	rootCmd.PersistentFlags().StringVar(&bucket, "bucket", "", "S3 bucket name")
 
 	// Global Service Configuration flags (shared across all commands)
 	rootCmd.PersistentFlags().StringVar(&accountID, "account-id", "", "Cloudflare R2 account ID (R2 only)")
 	rootCmd.PersistentFlags().StringVar(&endpoint, "endpoint", "", "Custom S3 endpoint")
 	rootCmd.PersistentFlags().StringVar(&region, "region", "", "S3 region")
+	rootCmd.PersistentFlags().StringVar(&addressingStyle, "addressing-style", "", "Bucket addressing: path or virtual (default: path for custom endpoints, virtual for AWS and R2)")
+
+	// Global Logging flags (logs go to stderr)
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
+	rootCmd.PersistentFlags().BoolVar(&debugHTTP, "debug-http", false, "Log every HTTP request and response, with credentials redacted (implies --log-level debug)")
 
 	// Input Configuration flags
-	uploadCmd.Flags().Int64VarP(&stdinSize, "size", "s", 0, "File size in bytes (required when reading from stdin)")
+	uploadCmd.Flags().Int64VarP(&stdinSize, "size", "s", 0, "File size in bytes when reading from stdin (optional)")
 
 	// Upload Tuning flags
 	uploadCmd.Flags().IntVarP(&workers, "workers", "w", 4, "Number of concurrent upload workers")
 	uploadCmd.Flags().IntVar(&queueSize, "queue", 10, "Part queue buffer size")
+	uploadCmd.Flags().BoolVar(&adaptive, "adaptive", false, "Reduce concurrency when the service throttles and raise it as parts succeed")
+	uploadCmd.Flags().IntVar(&minWorkers, "min-workers", 1, "Lowest concurrency --adaptive may drop to")
 	uploadCmd.Flags().IntVar(&maxMemory, "max-memory", 0, "Maximum memory usage in MB (0 = no limit)")
 	uploadCmd.Flags().Int64Var(&minPartSize, "min-part-size", 5*1024*1024, "Minimum part size in bytes")
 	uploadCmd.Flags().Int64Var(&maxPartSize, "max-part-size", 5*1024*1024*1024, "Maximum part size in bytes")
 	uploadCmd.Flags().IntVar(&maxParts, "max-parts", 10000, "Maximum number of parts")
+	uploadCmd.Flags().Int64Var(&singlePartThreshold, "single-part-threshold", 0, "Upload objects of up to this many bytes in one request (0 = min part size, -1 = always multipart)")
+	uploadCmd.Flags().StringVar(&limitRate, "limit-rate", "", "Maximum upload rate in bytes per second (e.g., 500K, 200M, 1G)")
 
 	// Retry Configuration flags
 	uploadCmd.Flags().IntVar(&maxRetries, "max-retries", 3, "Maximum retry attempts per part")
 	uploadCmd.Flags().IntVar(&retryDelay, "retry-delay", 1000, "Initial retry delay in milliseconds")
 	uploadCmd.Flags().IntVar(&maxRetryDelay, "max-retry-delay", 30000, "Maximum retry delay in milliseconds")
 	uploadCmd.Flags().IntVar(&retryMultiplier, "retry-multiplier", 2, "Backoff multiplier for retries")
+	uploadCmd.Flags().IntVar(&retryBudget, "retry-budget", 0, "Maximum retries across the whole upload (0 = unlimited)")
+	uploadCmd.Flags().DurationVar(&requestTimeout, "request-timeout", 5*time.Minute, "Timeout for each attempt to create, complete or abort the upload")
+	uploadCmd.Flags().DurationVar(&abortTimeout, "abort-timeout", time.Minute, "Total time to spend aborting a failed or interrupted upload")
+	uploadCmd.Flags().DurationVar(&partTimeout, "part-timeout", 0, "Retry a part attempt that takes longer than this (0 = no timeout)")
+	uploadCmd.Flags().Float64Var(&hedgeMultiplier, "hedge", 0, "Send a part again in parallel once it takes this many times the recent median (0 = off)")
+	uploadCmd.Flags().DurationVar(&sourceTimeout, "source-timeout", 0, "Fail if stdin or the source URL sends no data for this long (0 = wait forever)")
+	uploadCmd.Flags().BoolVar(&strictSize, "strict-size", false, "Abort if the source is not exactly its known size (from the file, the URL's Content-Length or --size)")
 
 	// Object Metadata flags
 	uploadCmd.Flags().StringVar(&contentType, "content-type", "", "Content-Type (auto-detected if not set)")
@@ -366,10 +463,29 @@ func init() {
 	uploadCmd.Flags().StringVar(&contentLanguage, "content-language", "", "Content-Language")
 	uploadCmd.Flags().StringVar(&cacheControl, "cache-control", "", "Cache-Control header")
 	uploadCmd.Flags().StringArrayVar(&metadata, "metadata", nil, "Custom metadata (key=value, repeatable)")
+	uploadCmd.Flags().BoolVar(&noOverwrite, "no-overwrite", false, "Fail instead of replacing an existing object (exit status 3)")
+	uploadCmd.Flags().StringVar(&ifMatch, "if-match", "", "Only replace the object if its current ETag matches (exit status 3 otherwise)")
+	uploadCmd.Flags().StringArrayVar(&tags, "tag", nil, "Object tag (key=value, repeatable, max 10)")
+	uploadCmd.Flags().StringVar(&storageClass, "storage-class", "", "Storage class (e.g., STANDARD_IA, GLACIER_IR, InfrequentAccess)")
+	uploadCmd.Flags().StringVar(&acl, "acl", "", "Canned ACL (e.g., private, bucket-owner-full-control)")
 
 	// Checksum flags
 	uploadCmd.Flags().BoolVar(&calculateChecksum, "checksum", true, "Calculate checksum during upload")
 	uploadCmd.Flags().StringVar(&checksumAlgorithm, "checksum-algorithm", "md5", "Checksum algorithm (md5, sha256)")
+	uploadCmd.Flags().StringVar(&serverChecksum, "server-checksum", "", "S3 additional checksum verified by the service (crc32, crc32c, crc64nvme, sha1, sha256)")
+	uploadCmd.Flags().StringVar(&serverChecksumType, "server-checksum-type", "", "Server checksum type (composite, full_object)")
+	uploadCmd.Flags().BoolVar(&sendContentMD5, "content-md5", false, "Send Content-MD5 with every part")
+	uploadCmd.Flags().StringVar(&compress, "compress", "", "Compress before upload (gzip, zstd, auto)")
+	uploadCmd.Flags().StringVar(&sse, "sse", "", "Server-side encryption (AES256, aws:kms, aws:kms:dsse)")
+	uploadCmd.Flags().StringVar(&sseKMSKeyID, "sse-kms-key-id", "", "KMS key ID or ARN for --sse aws:kms")
+	uploadCmd.Flags().StringArrayVar(&sseKMSContext, "sse-kms-context", nil, "KMS encryption context (key=value, repeatable)")
+	uploadCmd.Flags().StringVar(&sseCKeyFile, "sse-c-key-file", "", "Encrypt server-side with the 32-byte SSE-C key in this file")
+	uploadCmd.Flags().StringVar(&encryptionKeyFile, "encryption-key-file", "", "Encrypt client-side with the 32-byte key in this file (raw, hex or base64)")
+	uploadCmd.Flags().StringVar(&encryptionKeyEnv, "encryption-key-env", "", "Encrypt client-side with the hex or base64 key in this environment variable")
+	uploadCmd.Flags().BoolVar(&verifyUpload, "verify", false, "Check the stored object's size and ETag after upload")
+
+	// Resume flags
+	uploadCmd.Flags().BoolVar(&resume, "resume", false, "Keep failed uploads and resume them on the next run")
 
 	// Output Configuration flags
 	uploadCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output")
@@ -380,6 +496,14 @@ func init() {
 	// Download command flags (reuse checksum flags from upload)
 	downloadCmd.Flags().BoolVar(&calculateChecksum, "checksum", true, "Calculate checksum during download")
 	downloadCmd.Flags().StringVar(&checksumAlgorithm, "checksum-algorithm", "md5", "Checksum algorithm (md5, sha256)")
+	downloadCmd.Flags().BoolVar(&decompress, "decompress", false, "Decompress gzip/zstd objects based on their Content-Encoding")
+	downloadCmd.Flags().StringVar(&sse, "sse", "", "Require this server-side encryption (AES256, aws:kms, aws:kms:dsse)")
+	downloadCmd.Flags().StringVar(&sseKMSKeyID, "sse-kms-key-id", "", "Require the object to be encrypted with this KMS key")
+	downloadCmd.Flags().StringVar(&sseCKeyFile, "sse-c-key-file", "", "Read an SSE-C object with the 32-byte key in this file")
+	downloadCmd.Flags().StringVar(&encryptionKeyFile, "encryption-key-file", "", "Decrypt with the 32-byte key in this file (raw, hex or base64)")
+	downloadCmd.Flags().StringVar(&encryptionKeyEnv, "encryption-key-env", "", "Decrypt with the hex or base64 key in this environment variable")
+	downloadCmd.Flags().StringVar(&limitRate, "limit-rate", "", "Maximum download rate in bytes per second (e.g., 500K, 200M, 1G)")
+	downloadCmd.Flags().BoolVar(&verifyServerChecksum, "verify-server-checksum", false, "Validate the body against the object's S3 additional checksum")
 
 	// List command flags
 	listCmd.Flags().IntVar(&listMaxKeys, "max-keys", 1000, "Maximum number of keys to return")
@@ -403,28 +527,49 @@ func runUpload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate required configuration
-	if accessKeyID == "" {
-		return fmt.Errorf("S3_ACCESS_KEY_ID or --access-key is required")
-	}
-	if secretAccessKey == "" {
-		return fmt.Errorf("S3_SECRET_ACCESS_KEY or --secret-key is required")
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 	if bucket == "" {
 		return fmt.Errorf("S3_BUCKET or --bucket is required")
 	}
 
+	maxBytesPerSecond, err := parseRate(limitRate)
+	if err != nil {
+		return fmt.Errorf("invalid --limit-rate: %w", err)
+	}
+
+	encryption, err := loadEncryption()
+	if err != nil {
+		return err
+	}
+
+	sseCKey, err := loadSSECustomerKey()
+	if err != nil {
+		return err
+	}
+
+	kmsContext := make(map[string]string)
+	for _, pair := range sseKMSContext {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid KMS context format %q, expected key=value", pair)
+		}
+		kmsContext[parts[0]] = parts[1]
+	}
+
 	// Determine input source type and open reader
 	var reader io.Reader
 	var fileSize int64
-	var err error
 
 	if source == "-" {
-		// Read from stdin
-		if stdinSize <= 0 {
-			return fmt.Errorf("--size flag is required when reading from stdin")
-		}
+		// Read from stdin (size is unknown unless --size is given)
 		reader = os.Stdin
-		fileSize = stdinSize
+		fileSize = streamup.UnknownSize
+		if stdinSize > 0 {
+			fileSize = stdinSize
+		}
 		if !quiet {
 			fmt.Fprintf(os.Stderr, "Reading from stdin\n")
 		}
@@ -475,54 +620,113 @@ func runUpload(cmd *cobra.Command, args []string) error {
 		metadataMap[parts[0]] = parts[1]
 	}
 
+	// Parse tags (limits are checked by the uploader)
+	tagMap := make(map[string]string)
+	for _, kv := range tags {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid tag format %q, expected key=value", kv)
+		}
+		tagMap[parts[0]] = parts[1]
+	}
+
 	// Create uploader configuration
 	cfg := streamup.Config{
-		AccessKeyID:        accessKeyID,
-		SecretAccessKey:    secretAccessKey,
-		Bucket:             bucket,
-		Key:                key,
-		FileSize:           fileSize,
-		AccountID:          accountID,
-		Endpoint:           endpoint,
-		Region:             region,
-		Workers:            workers,
-		QueueSize:          queueSize,
-		MaxMemoryMB:        maxMemory,
-		ServiceLimits:      &limits,
-		MaxRetries:         maxRetries,
-		RetryDelay:         retryDelay,
-		MaxRetryDelay:      maxRetryDelay,
-		RetryMultiplier:    retryMultiplier,
-		ContentType:        contentType,
-		ContentDisposition: contentDisposition,
-		ContentEncoding:    contentEncoding,
-		ContentLanguage:    contentLanguage,
-		CacheControl:       cacheControl,
-		Metadata:           metadataMap,
-		CalculateChecksum:  calculateChecksum,
-		ChecksumAlgorithm:  checksumAlgorithm,
+		Bucket:                  bucket,
+		Key:                     key,
+		FileSize:                fileSize,
+		Workers:                 workers,
+		QueueSize:               queueSize,
+		MaxMemoryMB:             maxMemory,
+		ServiceLimits:           &limits,
+		SinglePartThreshold:     singlePartThreshold,
+		MaxRetries:              maxRetries,
+		RetryDelay:              retryDelay,
+		MaxRetryDelay:           maxRetryDelay,
+		RetryMultiplier:         retryMultiplier,
+		RetryBudget:             retryBudget,
+		RequestTimeout:          requestTimeout,
+		AbortTimeout:            abortTimeout,
+		PartTimeout:             partTimeout,
+		HedgeMultiplier:         hedgeMultiplier,
+		SourceIdleTimeout:       sourceTimeout,
+		StrictSize:              strictSize,
+		ContentType:             contentType,
+		ContentDisposition:      contentDisposition,
+		ContentEncoding:         contentEncoding,
+		ContentLanguage:         contentLanguage,
+		CacheControl:            cacheControl,
+		Metadata:                metadataMap,
+		IfNoneMatch:             noOverwrite,
+		IfMatch:                 ifMatch,
+		Tags:                    tagMap,
+		StorageClass:            storageClass,
+		ACL:                     acl,
+		CalculateChecksum:       calculateChecksum,
+		ChecksumAlgorithm:       checksumAlgorithm,
+		ServerChecksumAlgorithm: serverChecksum,
+		ServerChecksumType:      serverChecksumType,
+		SendContentMD5:          sendContentMD5,
+		VerifyUpload:            verifyUpload,
+		MaxBytesPerSecond:       maxBytesPerSecond,
+		AdaptiveConcurrency:     adaptive,
+		MinWorkers:              minWorkers,
+		Encryption:              encryption,
+		ServerSideEncryption:    sse,
+		SSEKMSKeyID:             sseKMSKeyID,
+		SSEKMSEncryptionContext: kmsContext,
+		SSECustomerKey:          sseCKey,
+		Compression:             compress,
+	}
+
+	// Keep a resume journal per bucket/key if requested
+	if resume {
+		journalPath, err := resumeJournalPath(bucket, key)
+		if err != nil {
+			return fmt.Errorf("failed to set up resume journal: %w", err)
+		}
+		cfg.ResumeJournal = journalPath
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "Resume journal: %s\n", journalPath)
+		}
 	}
 
 	// Create progress bar if not quiet
 	var bar *progressbar.ProgressBar
+	var uploader *streamup.Uploader
 	if !quiet {
 		bar = progressbar.DefaultBytes(
 			fileSize,
 			"Uploading",
 		)
-		cfg.ProgressCallback = func(bytesUploaded int64, partsUploaded int32) {
-			bar.Set64(bytesUploaded)
-		}
+		cfg.Observer = streamup.ObserverFunc(func(event streamup.Event) {
+			switch e := event.(type) {
+			case streamup.PartRetry:
+				bar.Describe(fmt.Sprintf("Uploading (retrying part %d, attempt %d)", e.Part, e.Attempt+1))
+			case streamup.PartCompleted:
+				// Show the current concurrency so --adaptive can be tuned
+				if adaptive {
+					bar.Describe(fmt.Sprintf("Uploading (%d/%d workers)", uploader.GetConcurrency(), workers))
+				} else {
+					bar.Describe("Uploading")
+				}
+				bytesUploaded, _ := uploader.GetProgress()
+				bar.Set64(bytesUploaded)
+			}
+		})
 	}
 
 	// Create uploader
-	uploader, err := streamup.New(cfg)
+	uploader, err = client.NewUploader(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create uploader: %w", err)
 	}
 
 	// Start upload
 	err = uploader.Upload(reader)
+	if errors.Is(err, streamup.ErrSourceStalled) && isURL(source) {
+		return fmt.Errorf("upload failed: %s sent no data for %s (the server or connection stalled): %w", source, sourceTimeout, err)
+	}
 	if err != nil {
 		return fmt.Errorf("upload failed: %w", err)
 	}
@@ -539,6 +743,14 @@ func runUpload(cmd *cobra.Command, args []string) error {
 				fmt.Fprintf(os.Stderr, "  %s: %s\n", checksumAlgorithm, checksum)
 			}
 		}
+		if serverChecksum != "" {
+			if checksum := uploader.GetServerChecksum(); checksum != "" {
+				fmt.Fprintf(os.Stderr, "  %s (server): %s\n", serverChecksum, checksum)
+			}
+		}
+		if result := uploader.GetVerification(); result != nil {
+			fmt.Fprintf(os.Stderr, "  verified: %d bytes, ETag %s\n", result.ActualSize, result.ActualETag)
+		}
 	}
 
 	return nil
@@ -558,11 +770,9 @@ func runDownload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate required configuration
-	if accessKeyID == "" {
-		return fmt.Errorf("S3_ACCESS_KEY_ID or --access-key is required")
-	}
-	if secretAccessKey == "" {
-		return fmt.Errorf("S3_SECRET_ACCESS_KEY or --secret-key is required")
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 	if bucket == "" {
 		return fmt.Errorf("S3_BUCKET or --bucket is required")
@@ -576,17 +786,34 @@ func runDownload(cmd *cobra.Command, args []string) error {
 	showProgress := !toStdout && !quiet
 
 	// Create downloader
+	maxBytesPerSecond, err := parseRate(limitRate)
+	if err != nil {
+		return fmt.Errorf("invalid --limit-rate: %w", err)
+	}
+
+	encryption, err := loadEncryption()
+	if err != nil {
+		return err
+	}
+
+	sseCKey, err := loadSSECustomerKey()
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
-	downloader, err := streamup.NewDownloader(streamup.DownloadConfig{
-		AccessKeyID:       accessKeyID,
-		SecretAccessKey:   secretAccessKey,
-		Bucket:            bucket,
-		Key:               key,
-		AccountID:         accountID,
-		Endpoint:          endpoint,
-		Region:            region,
-		CalculateChecksum: calculateChecksum,
-		ChecksumAlgorithm: checksumAlgorithm,
+	downloader, err := client.NewDownloader(streamup.DownloadConfig{
+		Bucket:               bucket,
+		Key:                  key,
+		CalculateChecksum:    calculateChecksum,
+		ChecksumAlgorithm:    checksumAlgorithm,
+		VerifyServerChecksum: verifyServerChecksum,
+		MaxBytesPerSecond:    maxBytesPerSecond,
+		Encryption:           encryption,
+		ServerSideEncryption: sse,
+		SSEKMSKeyID:          sseKMSKeyID,
+		SSECustomerKey:       sseCKey,
+		Decompress:           decompress,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create downloader: %w", err)
@@ -659,11 +886,9 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate required configuration
-	if accessKeyID == "" {
-		return fmt.Errorf("S3_ACCESS_KEY_ID or --access-key is required")
-	}
-	if secretAccessKey == "" {
-		return fmt.Errorf("S3_SECRET_ACCESS_KEY or --secret-key is required")
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 	if bucket == "" {
 		return fmt.Errorf("S3_BUCKET or --bucket is required")
@@ -671,15 +896,10 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Create lister
 	ctx := context.Background()
-	lister, err := streamup.NewLister(streamup.ListConfig{
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		Bucket:          bucket,
-		AccountID:       accountID,
-		Endpoint:        endpoint,
-		Region:          region,
-		Prefix:          prefix,
-		MaxKeys:         listMaxKeys,
+	lister, err := client.NewLister(streamup.ListConfig{
+		Bucket:  bucket,
+		Prefix:  prefix,
+		MaxKeys: listMaxKeys,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create lister: %w", err)
@@ -745,6 +965,147 @@ func formatSize(bytes int64) string {
 	return fmt.Sprintf("%.2f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// parseRate parses a byte rate such as "500K", "200M" or "1G" (1024-based).
+// An empty string means no limit.
+func parseRate(rate string) (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(rate))
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	if n := len(s); n > 0 {
+		if i := strings.IndexByte("KMGT", s[n-1]); i >= 0 {
+			multiplier = int64(1) << (10 * (i + 1))
+			s = s[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%q is not a positive rate", rate)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// newClient creates the S3 client from the global connection flags.
+func newClient() (*streamup.Client, error) {
+	logger, err := newLogger()
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	return streamup.NewClient(streamup.ClientConfig{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		Credentials:     creds,
+		AccountID:       accountID,
+		Endpoint:        endpoint,
+		Region:          region,
+		AddressingStyle: addressingStyle,
+		Logger:          logger,
+		DebugHTTP:       debugHTTP,
+	})
+}
+
+// newLogger creates the stderr logger for --log-level and --log-format.
+func newLogger() (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return nil, fmt.Errorf("invalid --log-level %q (use debug, info, warn or error)", logLevel)
+	}
+	// HTTP dumps are logged at debug level
+	if debugHTTP {
+		level = slog.LevelDebug
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch logFormat {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid --log-format %q (use text or json)", logFormat)
+	}
+}
+
+// loadCredentials returns a credential provider when --profile or
+// --use-default-credentials is set, which takes precedence over static keys.
+// Otherwise it returns nil and checks that static keys were given.
+func loadCredentials() (aws.CredentialsProvider, error) {
+	if profile != "" || useDefaultCredentials {
+		return streamup.DefaultCredentials(context.Background(), profile)
+	}
+
+	if accessKeyID == "" {
+		return nil, fmt.Errorf("S3_ACCESS_KEY_ID or --access-key is required (or use --profile or --use-default-credentials)")
+	}
+	if secretAccessKey == "" {
+		return nil, fmt.Errorf("S3_SECRET_ACCESS_KEY or --secret-key is required")
+	}
+	return nil, nil
+}
+
+// loadEncryption builds the client-side encryption config from
+// --encryption-key-file or --encryption-key-env. Returns nil if neither is set.
+func loadEncryption() (*streamup.EncryptionConfig, error) {
+	var key []byte
+	var err error
+
+	switch {
+	case encryptionKeyFile != "" && encryptionKeyEnv != "":
+		return nil, fmt.Errorf("use only one of --encryption-key-file and --encryption-key-env")
+	case encryptionKeyFile != "":
+		key, err = streamup.LoadEncryptionKey(encryptionKeyFile)
+	case encryptionKeyEnv != "":
+		key, err = streamup.EncryptionKeyFromEnv(encryptionKeyEnv)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	return &streamup.EncryptionConfig{Key: key}, nil
+}
+
+// loadSSECustomerKey reads the --sse-c-key-file key. Returns nil if not set.
+func loadSSECustomerKey() ([]byte, error) {
+	if sseCKeyFile == "" {
+		return nil, nil
+	}
+	key, err := streamup.LoadSSECustomerKey(sseCKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSE-C key: %w", err)
+	}
+	return key, nil
+}
+
+// resumeJournalPath returns the journal file used by --resume for a given object.
+// Journals live in the user's cache directory, named after a hash of the bucket and key.
+func resumeJournalPath(bucket, key string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cacheDir, "streamup", "journals")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(bucket + "/" + key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".journal"), nil
+}
+
 // isURL checks if a string is a valid URL.
 func isURL(s string) bool {
 	u, err := url.Parse(s)
@@ -1002,11 +1363,9 @@ func openURL(url string) (io.Reader, int64, error) {
 
 func runCleanup(cmd *cobra.Command, args []string) error {
 	// Validate required configuration
-	if accessKeyID == "" {
-		return fmt.Errorf("S3_ACCESS_KEY_ID or --access-key is required")
-	}
-	if secretAccessKey == "" {
-		return fmt.Errorf("S3_SECRET_ACCESS_KEY or --secret-key is required")
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 	if bucket == "" {
 		return fmt.Errorf("S3_BUCKET or --bucket is required")
@@ -1024,21 +1383,16 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 
 	// Create cleanup configuration
 	cfg := streamup.CleanupConfig{
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		Bucket:          bucket,
-		AccountID:       accountID,
-		Endpoint:        endpoint,
-		Region:          region,
-		Prefix:          cleanupPrefix,
-		OlderThan:       olderThan,
-		MaxResults:      cleanupMaxResults,
-		DryRun:          cleanupDryRun,
+		Bucket:     bucket,
+		Prefix:     cleanupPrefix,
+		OlderThan:  olderThan,
+		MaxResults: cleanupMaxResults,
+		DryRun:     cleanupDryRun,
 	}
 
 	// Run cleanup
 	ctx := context.Background()
-	result, err := streamup.CleanupIncompleteUploads(ctx, cfg)
+	result, err := client.CleanupIncompleteUploads(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("cleanup failed: %w", err)
 	}
@@ -1113,6 +1467,11 @@ func main() {
 	defer cancel()
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// Let scripts tell "object already exists/changed" apart from other failures
+		var precondErr *streamup.PreconditionFailedError
+		if errors.As(err, &precondErr) {
+			os.Exit(exitPreconditionFailed)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,81 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// bufferPool hands out a fixed number of reusable part buffers.
+//
+// The producer takes a buffer before reading each part and the worker returns
+// it once the part has been uploaded, so at most `slots` buffers exist at any
+// time. This makes partSize × (workers + queueSize) a hard ceiling on part
+// memory rather than an estimate, and avoids allocating a new buffer per part.
+type bufferPool struct {
+	buffers chan []byte
+}
+
+// newBufferPool creates a pool with the given number of slots.
+// Buffers are allocated lazily, so small uploads only use what they need.
+func newBufferPool(slots int) *bufferPool {
+	p := &bufferPool{buffers: make(chan []byte, slots)}
+	for i := 0; i < slots; i++ {
+		p.buffers <- nil
+	}
+	return p
+}
+
+// get waits for a free buffer and returns it resized to size bytes.
+// A buffer that is too small (e.g. when part sizes grow) is replaced.
+func (p *bufferPool) get(ctx context.Context, size int64) ([]byte, error) {
+	select {
+	case buf := <-p.buffers:
+		if int64(cap(buf)) < size {
+			buf = make([]byte, size)
+		}
+		return buf[:size], nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// put returns a buffer to the pool.
+func (p *bufferPool) put(buf []byte) {
+	p.buffers <- buf
+}
+
+// replay returns a reader over buf that puts buf back in the pool once it has
+// been read to the end.
+func (p *bufferPool) replay(buf []byte) io.Reader {
+	return &replayReader{reader: bytes.NewReader(buf), pool: p, buf: buf}
+}
+
+type replayReader struct {
+	reader *bytes.Reader
+	pool   *bufferPool
+	buf    []byte // Nil once returned to the pool
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF && r.buf != nil {
+		r.pool.put(r.buf)
+		r.buf = nil
+	}
+	return n, err
+}
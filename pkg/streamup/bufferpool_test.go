@@ -0,0 +1,154 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestBufferPool_Bounded(t *testing.T) {
+	pool := newBufferPool(2)
+	ctx := context.Background()
+
+	// Take every slot
+	for i := 0; i < 2; i++ {
+		if _, err := pool.get(ctx, 1024); err != nil {
+			t.Fatalf("get() unexpected error = %v", err)
+		}
+	}
+
+	// The next get must block until the context ends
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := pool.get(ctx, 1024); err != context.DeadlineExceeded {
+		t.Errorf("get() on exhausted pool error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestBufferPool_Reuse(t *testing.T) {
+	pool := newBufferPool(1)
+	ctx := context.Background()
+
+	buf, err := pool.get(ctx, 1024)
+	if err != nil {
+		t.Fatalf("get() unexpected error = %v", err)
+	}
+	if len(buf) != 1024 {
+		t.Errorf("len(buf) = %d, want 1024", len(buf))
+	}
+	first := &buf[0]
+	pool.put(buf[:10])
+
+	// A smaller or equal request reuses the same backing array
+	buf, err = pool.get(ctx, 512)
+	if err != nil {
+		t.Fatalf("get() unexpected error = %v", err)
+	}
+	if len(buf) != 512 {
+		t.Errorf("len(buf) = %d, want 512", len(buf))
+	}
+	if &buf[0] != first {
+		t.Error("get() allocated a new buffer instead of reusing the pooled one")
+	}
+	pool.put(buf)
+
+	// A larger request replaces the buffer
+	buf, err = pool.get(ctx, 4096)
+	if err != nil {
+		t.Fatalf("get() unexpected error = %v", err)
+	}
+	if len(buf) != 4096 {
+		t.Errorf("len(buf) = %d, want 4096", len(buf))
+	}
+}
+
+func TestBufferPool_Replay(t *testing.T) {
+	pool := newBufferPool(1)
+	ctx := context.Background()
+
+	buf, err := pool.get(ctx, 4)
+	if err != nil {
+		t.Fatalf("get() unexpected error = %v", err)
+	}
+	copy(buf, "data")
+	reader := pool.replay(buf)
+
+	// The slot stays taken until the replay has been read
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.get(short, 4); err != context.DeadlineExceeded {
+		t.Errorf("get() during replay error = %v, want context.DeadlineExceeded", err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "data" {
+		t.Fatalf("ReadAll() = %q, %v, want %q", data, err, "data")
+	}
+	if _, err := pool.get(ctx, 4); err != nil {
+		t.Errorf("get() after replay unexpected error = %v", err)
+	}
+}
+
+func TestUpload_ReadAheadUsesPool(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"single part", 1024},
+		{"multipart", 12 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			uploader := newFakeUploader(t, fake, Config{FileSize: UnknownSize})
+			if err := uploader.Upload(bytes.NewReader(testData(tt.size))); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			// Every buffer, including the read-ahead, is back in the pool
+			if free, slots := len(uploader.pool.buffers), cap(uploader.pool.buffers); free != slots {
+				t.Errorf("%d of %d pool slots free after Upload", free, slots)
+			}
+		})
+	}
+}
+
+func TestUpload_ReadAheadWaitsForPool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	fake := streamuptest.NewFake()
+	uploader := newFakeUploader(t, fake, Config{FileSize: 1024, Context: ctx})
+
+	// With every slot taken the read-ahead can't allocate past the limit
+	for range cap(uploader.pool.buffers) {
+		<-uploader.pool.buffers
+	}
+	if err := uploader.Upload(bytes.NewReader(testData(1024))); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Upload() error = %v, want context.DeadlineExceeded", err)
+	}
+	if fake.Calls(streamuptest.OpPutObject) != 0 {
+		t.Error("nothing should be sent without a buffer")
+	}
+}
@@ -117,6 +117,100 @@ func CalculateOptimalPartSize(fileSize int64, maxMemoryMB, workers, queueSize in
 	return partSize, nil
 }
 
+// PartSizeSchedule describes how part sizes grow over the course of an upload.
+//
+// Parts start at InitialSize and double every GrowthInterval parts until they
+// reach MaxSize. A GrowthInterval of 0 means every part uses InitialSize, which
+// is the schedule used when the file size is known up front.
+type PartSizeSchedule struct {
+	InitialSize    int64 // Size of the first part in bytes
+	MaxSize        int64 // Largest part size the schedule will grow to
+	GrowthInterval int   // Number of parts between each doubling (0 = fixed size)
+}
+
+// PartSize returns the size in bytes of the given (1-based) part number.
+func (s PartSizeSchedule) PartSize(partNumber int32) int64 {
+	size := s.InitialSize
+	if s.GrowthInterval <= 0 || partNumber <= 1 {
+		return size
+	}
+
+	doublings := int(partNumber-1) / s.GrowthInterval
+	for i := 0; i < doublings && size < s.MaxSize; i++ {
+		size *= 2
+	}
+	if size > s.MaxSize {
+		size = s.MaxSize
+	}
+
+	return size
+}
+
+// Capacity returns the total number of bytes that fit in maxParts parts.
+func (s PartSizeSchedule) Capacity(maxParts int) int64 {
+	var total int64
+	for n := 1; n <= maxParts; n++ {
+		total += s.PartSize(int32(n))
+	}
+	return total
+}
+
+// CalculatePartSizeSchedule determines a growing part size schedule for
+// streams whose total size is not known in advance (see UnknownSize).
+//
+// Parts start at the service minimum so small streams stay cheap, then double
+// at regular intervals so that the last interval of parts reaches the largest
+// allowed part size. This lets the maximum number of parts hold multi-TB
+// streams while memory usage only grows for streams that turn out to be large.
+//
+// The largest part size is capped by the memory constraint using the same
+// formula as CalculateOptimalPartSize: partSize × (workers + queueSize). It is
+// an error if that leaves less than the minimum part size.
+//
+// Examples (S3 limits):
+//   - No memory limit → 5 MB parts doubling every 909 parts up to 5 GB (~8.8 TB)
+//   - With 1GB limit → 5 MB parts doubling every 2000 parts up to 73 MB (~289 GB)
+func CalculatePartSizeSchedule(maxMemoryMB, workers, queueSize int, limits ServiceLimits) (PartSizeSchedule, error) {
+	if err := limits.Validate(); err != nil {
+		return PartSizeSchedule{}, err
+	}
+
+	// Largest part we are allowed to grow to
+	maxSize := limits.MaxPartSize
+	if maxMemoryMB > 0 {
+		totalSlots := workers + queueSize
+		memoryConstrainedPartSize := roundToNearestMB(int64(maxMemoryMB) * mbSize / int64(totalSlots))
+		if memoryConstrainedPartSize < limits.MinPartSize {
+			return PartSizeSchedule{}, fmt.Errorf("memory limit of %d MB is too small for %d buffers of the minimum part size (%d MB); need at least %d MB",
+				maxMemoryMB, totalSlots, limits.MinPartSize/mbSize, CalculateMemoryUsage(limits.MinPartSize, workers, queueSize)/mbSize)
+		}
+		if memoryConstrainedPartSize < maxSize {
+			maxSize = memoryConstrainedPartSize
+		}
+	}
+
+	// Count how many doublings it takes to get from the minimum to the maximum
+	doublings := 0
+	for size := limits.MinPartSize; size < maxSize; size *= 2 {
+		doublings++
+	}
+
+	schedule := PartSizeSchedule{
+		InitialSize: limits.MinPartSize,
+		MaxSize:     maxSize,
+	}
+
+	// Spread the doublings evenly so every size gets an equal share of parts
+	if doublings > 0 {
+		schedule.GrowthInterval = limits.MaxParts / (doublings + 1)
+		if schedule.GrowthInterval < 1 {
+			schedule.GrowthInterval = 1
+		}
+	}
+
+	return schedule, nil
+}
+
 // roundToNearestMB rounds a size to the nearest megabyte.
 func roundToNearestMB(size int64) int64 {
 	remainder := size % mbSize
@@ -126,7 +220,8 @@ func roundToNearestMB(size int64) int64 {
 	return size + (mbSize - remainder)
 }
 
-// CalculateMemoryUsage estimates total memory usage for given parameters.
+// CalculateMemoryUsage returns the memory used by part buffers for the given
+// parameters. The uploader's buffer pool never holds more than this at once.
 func CalculateMemoryUsage(partSize int64, workers, queueSize int) int64 {
 	return partSize * int64(workers+queueSize)
 }
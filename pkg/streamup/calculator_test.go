@@ -305,6 +305,126 @@ func TestCalculateOptimalPartSize_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestCalculatePartSizeSchedule(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxMemoryMB     int
+		workers         int
+		queueSize       int
+		limits          ServiceLimits
+		wantInitial     int64
+		wantMax         int64
+		wantInterval    int
+		wantMinCapacity int64
+	}{
+		{
+			name:            "No memory limit grows to 5GB parts",
+			maxMemoryMB:     0,
+			workers:         4,
+			queueSize:       10,
+			limits:          DefaultS3Limits(),
+			wantInitial:     5 * 1024 * 1024,
+			wantMax:         5 * 1024 * 1024 * 1024,
+			wantInterval:    909,
+			wantMinCapacity: 8 * 1024 * 1024 * 1024 * 1024, // 8 TB
+		},
+		{
+			name:            "1GB memory limit caps part size",
+			maxMemoryMB:     1024,
+			workers:         4,
+			queueSize:       10,
+			limits:          DefaultS3Limits(),
+			wantInitial:     5 * 1024 * 1024,
+			wantMax:         73 * 1024 * 1024,
+			wantInterval:    2000,
+			wantMinCapacity: 280 * 1024 * 1024 * 1024, // 280 GB
+		},
+		{
+			name:        "Memory limit for minimum parts only",
+			maxMemoryMB: 70,
+			workers:     4,
+			queueSize:   10,
+			limits:      DefaultS3Limits(),
+			wantInitial: 5 * 1024 * 1024,
+			wantMax:     5 * 1024 * 1024,
+			// No growth possible
+			wantInterval:    0,
+			wantMinCapacity: 10000 * 5 * 1024 * 1024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := CalculatePartSizeSchedule(tt.maxMemoryMB, tt.workers, tt.queueSize, tt.limits)
+			if err != nil {
+				t.Fatalf("CalculatePartSizeSchedule() unexpected error = %v", err)
+			}
+
+			if schedule.InitialSize != tt.wantInitial {
+				t.Errorf("InitialSize = %d, want %d", schedule.InitialSize, tt.wantInitial)
+			}
+			if schedule.MaxSize != tt.wantMax {
+				t.Errorf("MaxSize = %d, want %d", schedule.MaxSize, tt.wantMax)
+			}
+			if schedule.GrowthInterval != tt.wantInterval {
+				t.Errorf("GrowthInterval = %d, want %d", schedule.GrowthInterval, tt.wantInterval)
+			}
+
+			// The last part must never exceed the maximum
+			last := schedule.PartSize(int32(tt.limits.MaxParts))
+			if last > tt.wantMax {
+				t.Errorf("PartSize(%d) = %d, exceeds MaxSize %d", tt.limits.MaxParts, last, tt.wantMax)
+			}
+
+			capacity := schedule.Capacity(tt.limits.MaxParts)
+			if capacity < tt.wantMinCapacity {
+				t.Errorf("Capacity() = %d, want at least %d", capacity, tt.wantMinCapacity)
+			}
+		})
+	}
+}
+
+func TestCalculatePartSizeSchedule_MemoryTooSmall(t *testing.T) {
+	// 10 MB can't hold 14 buffers of 5 MB
+	_, err := CalculatePartSizeSchedule(10, 4, 10, DefaultS3Limits())
+	if err == nil {
+		t.Fatal("CalculatePartSizeSchedule() expected error for a memory limit below the minimum part size")
+	}
+	if !contains(err.Error(), "need at least 70 MB") {
+		t.Errorf("CalculatePartSizeSchedule() error = %v, want the memory needed", err)
+	}
+}
+
+func TestPartSizeSchedule_PartSize(t *testing.T) {
+	schedule := PartSizeSchedule{
+		InitialSize:    5 * 1024 * 1024,
+		MaxSize:        15 * 1024 * 1024,
+		GrowthInterval: 2,
+	}
+
+	expected := []int64{
+		5 * 1024 * 1024,  // part 1
+		5 * 1024 * 1024,  // part 2
+		10 * 1024 * 1024, // part 3 (first doubling)
+		10 * 1024 * 1024, // part 4
+		15 * 1024 * 1024, // part 5 (capped at max)
+		15 * 1024 * 1024, // part 6
+	}
+
+	for i, want := range expected {
+		got := schedule.PartSize(int32(i + 1))
+		if got != want {
+			t.Errorf("PartSize(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// Fixed schedules never grow
+	fixed := PartSizeSchedule{InitialSize: 8 * 1024 * 1024, MaxSize: 8 * 1024 * 1024}
+	if got := fixed.PartSize(5000); got != 8*1024*1024 {
+		t.Errorf("fixed PartSize(5000) = %d, want %d", got, 8*1024*1024)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && containsHelper(s, substr))
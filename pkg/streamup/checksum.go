@@ -0,0 +1,159 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Server-side checksum algorithms supported by S3 additional checksums.
+// These are sent to and verified by the storage service, unlike
+// Config.ChecksumAlgorithm which is only calculated locally.
+const (
+	ServerChecksumCRC32     = "crc32"
+	ServerChecksumCRC32C    = "crc32c"
+	ServerChecksumCRC64NVME = "crc64nvme"
+	ServerChecksumSHA1      = "sha1"
+	ServerChecksumSHA256    = "sha256"
+)
+
+// Server-side checksum types, controlling how the object checksum is derived
+// from the part checksums.
+const (
+	// ServerChecksumComposite stores a checksum of the part checksums ("-N" suffix).
+	ServerChecksumComposite = "composite"
+	// ServerChecksumFullObject stores a checksum of the whole object (CRC algorithms only).
+	ServerChecksumFullObject = "full_object"
+)
+
+// crc64NVMETable is the reflected CRC-64/NVME polynomial used by S3.
+var crc64NVMETable = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+
+// newServerChecksumHash returns a hash for the given server checksum algorithm,
+// or nil if the algorithm is not supported.
+func newServerChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case ServerChecksumCRC32:
+		return crc32.NewIEEE()
+	case ServerChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case ServerChecksumCRC64NVME:
+		return crc64.New(crc64NVMETable)
+	case ServerChecksumSHA1:
+		return sha1.New()
+	case ServerChecksumSHA256:
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// serverChecksumAlgorithm maps an algorithm name to the S3 enum value.
+func serverChecksumAlgorithm(algorithm string) types.ChecksumAlgorithm {
+	switch algorithm {
+	case ServerChecksumCRC32:
+		return types.ChecksumAlgorithmCrc32
+	case ServerChecksumCRC32C:
+		return types.ChecksumAlgorithmCrc32c
+	case ServerChecksumCRC64NVME:
+		return types.ChecksumAlgorithmCrc64nvme
+	case ServerChecksumSHA1:
+		return types.ChecksumAlgorithmSha1
+	case ServerChecksumSHA256:
+		return types.ChecksumAlgorithmSha256
+	default:
+		return ""
+	}
+}
+
+// serverChecksumType maps a checksum type name to the S3 enum value.
+func serverChecksumType(checksumType string) types.ChecksumType {
+	switch checksumType {
+	case ServerChecksumComposite:
+		return types.ChecksumTypeComposite
+	case ServerChecksumFullObject:
+		return types.ChecksumTypeFullObject
+	default:
+		return ""
+	}
+}
+
+// computeServerChecksum returns the base64-encoded checksum of data, as
+// expected by the x-amz-checksum-* headers.
+func computeServerChecksum(algorithm string, data []byte) string {
+	h := newServerChecksumHash(algorithm)
+	if h == nil {
+		return ""
+	}
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// computeContentMD5 returns the base64-encoded MD5 of data for the Content-MD5 header.
+func computeContentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// checksumFields holds one value per S3 checksum header; at most one is set.
+type checksumFields struct {
+	crc32     *string
+	crc32c    *string
+	crc64nvme *string
+	sha1      *string
+	sha256    *string
+}
+
+// newChecksumFields places a checksum value in the field for its algorithm.
+func newChecksumFields(algorithm, value string) checksumFields {
+	var f checksumFields
+	if value == "" {
+		return f
+	}
+
+	switch algorithm {
+	case ServerChecksumCRC32:
+		f.crc32 = aws.String(value)
+	case ServerChecksumCRC32C:
+		f.crc32c = aws.String(value)
+	case ServerChecksumCRC64NVME:
+		f.crc64nvme = aws.String(value)
+	case ServerChecksumSHA1:
+		f.sha1 = aws.String(value)
+	case ServerChecksumSHA256:
+		f.sha256 = aws.String(value)
+	}
+
+	return f
+}
+
+// value returns whichever checksum is set, or an empty string.
+func (f checksumFields) value() string {
+	for _, v := range []*string{f.crc32, f.crc32c, f.crc64nvme, f.sha1, f.sha256} {
+		if v != nil {
+			return *v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+func TestComputeServerChecksum(t *testing.T) {
+	data := []byte("123456789")
+
+	// Standard check values for each CRC over "123456789"
+	crc64Check := make([]byte, 8)
+	binary.BigEndian.PutUint64(crc64Check, 0xae8b14860a799888)
+
+	tests := []struct {
+		algorithm string
+		want      string
+	}{
+		{ServerChecksumCRC32, base64.StdEncoding.EncodeToString([]byte{0xcb, 0xf4, 0x39, 0x26})},
+		{ServerChecksumCRC32C, base64.StdEncoding.EncodeToString([]byte{0xe3, 0x06, 0x92, 0x83})},
+		{ServerChecksumCRC64NVME, base64.StdEncoding.EncodeToString(crc64Check)},
+		{ServerChecksumSHA1, "98O8HYCOBHMq32eZZczDTKeuNEE="},
+		{ServerChecksumSHA256, "FeKw08M4keuw8e9gnsQZQgwg4yDOlMZfvIwzEkSOsiU="},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			if got := computeServerChecksum(tt.algorithm, data); got != tt.want {
+				t.Errorf("computeServerChecksum(%q) = %q, want %q", tt.algorithm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChecksumFields(t *testing.T) {
+	f := newChecksumFields(ServerChecksumCRC32C, "abc=")
+	if f.crc32c == nil || *f.crc32c != "abc=" {
+		t.Errorf("crc32c = %v, want abc=", f.crc32c)
+	}
+	if f.crc32 != nil || f.crc64nvme != nil || f.sha1 != nil || f.sha256 != nil {
+		t.Error("newChecksumFields() set a field for the wrong algorithm")
+	}
+	if got := f.value(); got != "abc=" {
+		t.Errorf("value() = %q, want abc=", got)
+	}
+
+	// An empty value leaves every header unset
+	if got := newChecksumFields(ServerChecksumSHA256, "").value(); got != "" {
+		t.Errorf("value() = %q, want empty", got)
+	}
+}
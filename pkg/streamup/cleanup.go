@@ -17,11 +17,10 @@ package streamup
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
@@ -38,6 +37,8 @@ type IncompleteUpload struct {
 type CleanupConfig struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	SessionToken    string                  // Optional session token for temporary keys
+	Credentials     aws.CredentialsProvider // Optional provider (overrides the static keys)
 	Bucket          string
 	AccountID       string // For R2
 	Endpoint        string
@@ -49,7 +50,8 @@ type CleanupConfig struct {
 	MaxResults int           // Maximum number of uploads to return (0 = all)
 
 	// Options
-	DryRun bool // If true, only list uploads without aborting
+	DryRun bool         // If true, only list uploads without aborting
+	Logger *slog.Logger // Optional logger for abort outcomes (default: the client's, or none)
 }
 
 // CleanupResult represents the result of a cleanup operation.
@@ -62,11 +64,16 @@ type CleanupResult struct {
 
 // ListIncompleteUploads lists all incomplete multipart uploads in a bucket.
 func ListIncompleteUploads(ctx context.Context, cfg CleanupConfig) ([]IncompleteUpload, error) {
-	s3Client, err := createS3Client(ctx, cfg)
+	client, err := newCleanupClient(cfg)
 	if err != nil {
 		return nil, err
 	}
+	return client.ListIncompleteUploads(ctx, cfg)
+}
 
+// ListIncompleteUploads lists all incomplete multipart uploads in a bucket
+// using the client's connection. The connection settings in cfg are ignored.
+func (c *Client) ListIncompleteUploads(ctx context.Context, cfg CleanupConfig) ([]IncompleteUpload, error) {
 	var uploads []IncompleteUpload
 	var continuationToken *string
 
@@ -92,7 +99,7 @@ func ListIncompleteUploads(ctx context.Context, cfg CleanupConfig) ([]Incomplete
 			input.MaxUploads = aws.Int32(int32(cfg.MaxResults))
 		}
 
-		result, err := s3Client.ListMultipartUploads(ctx, input)
+		result, err := c.s3Client.ListMultipartUploads(ctx, input)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list multipart uploads: %w", err)
 		}
@@ -139,8 +146,18 @@ func ListIncompleteUploads(ctx context.Context, cfg CleanupConfig) ([]Incomplete
 
 // CleanupIncompleteUploads aborts incomplete multipart uploads.
 func CleanupIncompleteUploads(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
+	client, err := newCleanupClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.CleanupIncompleteUploads(ctx, cfg)
+}
+
+// CleanupIncompleteUploads aborts incomplete multipart uploads using the
+// client's connection. The connection settings in cfg are ignored.
+func (c *Client) CleanupIncompleteUploads(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
 	// List incomplete uploads
-	uploads, err := ListIncompleteUploads(ctx, cfg)
+	uploads, err := c.ListIncompleteUploads(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -156,13 +173,9 @@ func CleanupIncompleteUploads(ctx context.Context, cfg CleanupConfig) (*CleanupR
 	}
 
 	// Abort each upload
-	s3Client, err := createS3Client(ctx, cfg)
-	if err != nil {
-		return nil, err
-	}
-
+	logger := c.logger(cfg.Logger)
 	for _, upload := range uploads {
-		_, err := s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		_, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
 			Bucket:   aws.String(cfg.Bucket),
 			Key:      aws.String(upload.Key),
 			UploadId: aws.String(upload.UploadID),
@@ -170,55 +183,28 @@ func CleanupIncompleteUploads(ctx context.Context, cfg CleanupConfig) (*CleanupR
 
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to abort %s (upload ID: %s): %w", upload.Key, upload.UploadID, err))
+			logger.Warn("failed to abort incomplete upload", append([]any{"bucket", cfg.Bucket,
+				"key", upload.Key, "upload_id", upload.UploadID}, errorAttrs(err)...)...)
 		} else {
 			result.TotalAborted++
+			logger.Info("aborted incomplete upload", "bucket", cfg.Bucket, "key", upload.Key,
+				"upload_id", upload.UploadID, "initiated", upload.Initiated)
 		}
 	}
 
 	return result, nil
 }
 
-// createS3Client creates an S3 client for cleanup operations.
-func createS3Client(ctx context.Context, cfg CleanupConfig) (*s3.Client, error) {
-	// Create credentials
-	creds := credentials.NewStaticCredentialsProvider(
-		cfg.AccessKeyID,
-		cfg.SecretAccessKey,
-		"",
-	)
-
-	// Set region default
-	region := cfg.Region
-	if region == "" {
-		if cfg.AccountID != "" {
-			region = "auto" // R2 default
-		} else {
-			region = "us-east-1" // S3 default
-		}
-	}
-
-	// Set endpoint default
-	endpoint := cfg.Endpoint
-	if endpoint == "" && cfg.AccountID != "" {
-		endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID)
-	}
-
-	// Create AWS config with custom User-Agent
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithCredentialsProvider(creds),
-		config.WithRegion(region),
-		config.WithAppID(UserAgent()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AWS config: %w", err)
-	}
-
-	// Create S3 client
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		if endpoint != "" {
-			o.BaseEndpoint = aws.String(endpoint)
-		}
+// newCleanupClient creates a client for cleanup operations.
+func newCleanupClient(cfg CleanupConfig) (*Client, error) {
+	return NewClient(ClientConfig{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+		Credentials:     cfg.Credentials,
+		AccountID:       cfg.AccountID,
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Logger:          cfg.Logger,
 	})
-
-	return s3Client, nil
 }
@@ -0,0 +1,220 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Addressing styles for ClientConfig.AddressingStyle.
+const (
+	AddressingAuto    = ""        // Path-style for custom endpoints (e.g. MinIO), virtual-hosted for AWS and R2
+	AddressingPath    = "path"    // https://endpoint/bucket/key
+	AddressingVirtual = "virtual" // https://bucket.endpoint/key
+)
+
+// S3API is the subset of the S3 API used by streamup. *s3.Client implements
+// it; tests can substitute an in-memory implementation such as
+// streamuptest.Fake.
+type S3API interface {
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	ListParts(ctx context.Context, params *s3.ListPartsInput, optFns ...func(*s3.Options)) (*s3.ListPartsOutput, error)
+	ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+var _ S3API = (*s3.Client)(nil)
+
+// ClientConfig holds the connection settings shared by uploads, downloads,
+// listings and cleanup.
+type ClientConfig struct {
+	// S3 Credentials: static keys, or a provider such as DefaultCredentials
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string                  // Optional session token for temporary keys
+	Credentials     aws.CredentialsProvider // Optional provider (overrides the static keys)
+
+	// Service Configuration
+	AccountID       string // Cloudflare R2 account ID (optional)
+	Endpoint        string // Custom S3 endpoint (optional)
+	Region          string // S3 region (default: auto for R2, us-east-1 for others)
+	AddressingStyle string // AddressingAuto, AddressingPath or AddressingVirtual
+
+	// HTTPClient replaces the SDK's default HTTP client (optional)
+	HTTPClient *http.Client
+
+	// SDK retry settings for individual requests. Uploads additionally retry
+	// failed parts themselves (see Config.MaxRetries).
+	MaxAttempts int           // Attempts per request, including the first (0 = SDK default)
+	MaxBackoff  time.Duration // Upper bound on the delay between attempts (0 = SDK default)
+
+	// API replaces the SDK client, e.g. with an in-memory fake for tests.
+	// Credentials and the settings above are then ignored.
+	API S3API
+
+	// Logging: Logger is the default for transfers created from the client.
+	// DebugHTTP logs every request and response at debug level, with
+	// credentials and other secrets redacted.
+	Logger    *slog.Logger
+	DebugHTTP bool
+}
+
+// validate checks the configuration and applies defaults.
+func (c *ClientConfig) validate() error {
+	if c.Credentials == nil && c.API == nil {
+		if c.AccessKeyID == "" {
+			return &ValidationError{Field: "AccessKeyID", Message: "required"}
+		}
+		if c.SecretAccessKey == "" {
+			return &ValidationError{Field: "SecretAccessKey", Message: "required"}
+		}
+	}
+
+	switch c.AddressingStyle {
+	case AddressingAuto, AddressingPath, AddressingVirtual:
+	default:
+		return &ValidationError{Field: "AddressingStyle", Message: "must be 'path' or 'virtual'"}
+	}
+	if c.MaxAttempts < 0 {
+		return &ValidationError{Field: "MaxAttempts", Message: "must not be negative"}
+	}
+	if c.MaxBackoff < 0 {
+		return &ValidationError{Field: "MaxBackoff", Message: "must not be negative"}
+	}
+
+	// Set region default
+	if c.Region == "" {
+		if c.AccountID != "" {
+			c.Region = "auto" // R2 default
+		} else {
+			c.Region = "us-east-1" // S3 default
+		}
+	}
+
+	// Auto-detect R2 endpoint if AccountID provided but Endpoint is not
+	if c.AccountID != "" && c.Endpoint == "" {
+		c.Endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", c.AccountID)
+	}
+
+	return nil
+}
+
+// usePathStyle reports whether requests address buckets by path.
+func (c *ClientConfig) usePathStyle() bool {
+	switch c.AddressingStyle {
+	case AddressingPath:
+		return true
+	case AddressingVirtual:
+		return false
+	default:
+		// R2 has always been addressed virtual-hosted
+		return c.Endpoint != "" && c.AccountID == ""
+	}
+}
+
+// Client is a connection to S3-compatible storage. It is safe for concurrent
+// use, and uploads, downloads, listings and cleanup created from the same
+// Client share its credentials cache and connection pool.
+type Client struct {
+	config      ClientConfig
+	credentials aws.CredentialsProvider
+	s3Client    S3API
+}
+
+// NewClient creates a client from connection settings.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.API != nil {
+		return &Client{
+			config:      cfg,
+			credentials: aws.AnonymousCredentials{},
+			s3Client:    cfg.API,
+		}, nil
+	}
+
+	creds := credentialsProvider(
+		cfg.Credentials,
+		cfg.AccessKeyID,
+		cfg.SecretAccessKey,
+		cfg.SessionToken,
+	)
+
+	// Create AWS config with custom User-Agent
+	awsCfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithCredentialsProvider(creds),
+		config.WithRegion(cfg.Region),
+		config.WithAppID(UserAgent()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.usePathStyle()
+		if cfg.HTTPClient != nil {
+			o.HTTPClient = cfg.HTTPClient
+		}
+		if cfg.MaxAttempts > 0 {
+			o.RetryMaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.MaxBackoff > 0 {
+			o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, cfg.MaxBackoff)
+		}
+		if cfg.DebugHTTP {
+			o.Logger = sdkLogger{logger: loggerOrDiscard(cfg.Logger)}
+			o.ClientLogMode = aws.LogRequest | aws.LogResponse | aws.LogRetries
+		}
+	})
+
+	return &Client{
+		config:      cfg,
+		credentials: awsCfg.Credentials,
+		s3Client:    s3Client,
+	}, nil
+}
+
+// logger returns override if set, or the client's logger.
+func (c *Client) logger(override *slog.Logger) *slog.Logger {
+	if override != nil {
+		return override
+	}
+	return loggerOrDiscard(c.config.Logger)
+}
+
+// Config returns the client's connection settings, with defaults applied.
+func (c *Client) Config() ClientConfig {
+	return c.config
+}
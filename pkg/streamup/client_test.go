@@ -0,0 +1,241 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+var _ S3API = (*streamuptest.Fake)(nil)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       ClientConfig
+		wantErr      bool
+		wantRegion   string
+		wantEndpoint string
+		wantPath     bool
+	}{
+		{
+			name:       "AWS defaults to virtual-hosted",
+			config:     ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret"},
+			wantRegion: "us-east-1",
+		},
+		{
+			name:         "R2 defaults",
+			config:       ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", AccountID: "abc"},
+			wantRegion:   "auto",
+			wantEndpoint: "https://abc.r2.cloudflarestorage.com",
+		},
+		{
+			name: "Path-style R2",
+			config: ClientConfig{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+				AccountID:       "abc",
+				AddressingStyle: AddressingPath,
+			},
+			wantRegion:   "auto",
+			wantEndpoint: "https://abc.r2.cloudflarestorage.com",
+			wantPath:     true,
+		},
+		{
+			name:         "Custom endpoint defaults to path-style",
+			config:       ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", Endpoint: "http://localhost:9000"},
+			wantRegion:   "us-east-1",
+			wantEndpoint: "http://localhost:9000",
+			wantPath:     true,
+		},
+		{
+			name: "Virtual-hosted custom endpoint",
+			config: ClientConfig{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+				Endpoint:        "https://storage.example.com",
+				AddressingStyle: AddressingVirtual,
+			},
+			wantRegion:   "us-east-1",
+			wantEndpoint: "https://storage.example.com",
+		},
+		{
+			name:       "Path-style on AWS",
+			config:     ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", AddressingStyle: AddressingPath},
+			wantRegion: "us-east-1",
+			wantPath:   true,
+		},
+		{
+			name:    "Missing credentials",
+			config:  ClientConfig{},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid addressing style",
+			config:  ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", AddressingStyle: "dns"},
+			wantErr: true,
+		},
+		{
+			name:    "Negative MaxAttempts",
+			config:  ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", MaxAttempts: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			cfg := client.Config()
+			if cfg.Region != tt.wantRegion {
+				t.Errorf("Region = %q, want %q", cfg.Region, tt.wantRegion)
+			}
+			if cfg.Endpoint != tt.wantEndpoint {
+				t.Errorf("Endpoint = %q, want %q", cfg.Endpoint, tt.wantEndpoint)
+			}
+			if got := client.s3Client.(*s3.Client).Options().UsePathStyle; got != tt.wantPath {
+				t.Errorf("UsePathStyle = %v, want %v", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestNewClient_Transport(t *testing.T) {
+	httpClient := &http.Client{}
+	client, err := NewClient(ClientConfig{
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		HTTPClient:      httpClient,
+		MaxAttempts:     7,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	opts := client.s3Client.(*s3.Client).Options()
+	if opts.HTTPClient != httpClient {
+		t.Error("HTTPClient was not used")
+	}
+	if opts.RetryMaxAttempts != 7 {
+		t.Errorf("RetryMaxAttempts = %d, want 7", opts.RetryMaxAttempts)
+	}
+	if opts.ClientLogMode != 0 {
+		t.Error("HTTP logging should be off by default")
+	}
+
+	client, err = NewClient(ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", DebugHTTP: true})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	opts = client.s3Client.(*s3.Client).Options()
+	if !opts.ClientLogMode.IsRequest() || !opts.ClientLogMode.IsResponse() || opts.ClientLogMode.IsRequestWithBody() {
+		t.Errorf("ClientLogMode = %v, want requests and responses without bodies", opts.ClientLogMode)
+	}
+	if _, ok := opts.Logger.(sdkLogger); !ok {
+		t.Errorf("Logger = %T, want the redacting logger", opts.Logger)
+	}
+}
+
+func TestClient_SharesConnection(t *testing.T) {
+	client, err := NewClient(ClientConfig{AccessKeyID: "id", SecretAccessKey: "secret", AccountID: "abc"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	uploader, err := client.NewUploader(Config{Bucket: "b", Key: "k", FileSize: 1024})
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	downloader, err := client.NewDownloader(DownloadConfig{Bucket: "b", Key: "k"})
+	if err != nil {
+		t.Fatalf("NewDownloader() error = %v", err)
+	}
+	lister, err := client.NewLister(ListConfig{Bucket: "b"})
+	if err != nil {
+		t.Fatalf("NewLister() error = %v", err)
+	}
+
+	if uploader.s3Client != client.s3Client || downloader.s3Client != client.s3Client || lister.s3Client != client.s3Client {
+		t.Error("operations should share the client's S3 client")
+	}
+	if uploader.config.Region != "auto" || uploader.config.Endpoint != "https://abc.r2.cloudflarestorage.com" {
+		t.Errorf("uploader connection = %q %q, want client's", uploader.config.Region, uploader.config.Endpoint)
+	}
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	fake := streamuptest.NewFake()
+	client, err := NewClient(ClientConfig{API: fake})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+	data := bytes.Repeat([]byte("streamup round trip "), 50000)
+
+	uploader, err := client.NewUploader(Config{
+		Bucket:      "b",
+		Key:         "data.txt",
+		FileSize:    int64(len(data)),
+		Compression: CompressionZstd,
+		Encryption:  &EncryptionConfig{Key: key},
+	})
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	downloader, err := client.NewDownloader(DownloadConfig{
+		Bucket:     "b",
+		Key:        "data.txt",
+		Encryption: &EncryptionConfig{Key: key},
+		Decompress: true,
+	})
+	if err != nil {
+		t.Fatalf("NewDownloader() error = %v", err)
+	}
+	var got bytes.Buffer
+	if err := downloader.Download(context.Background(), &got); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), data) {
+		t.Error("downloaded data does not match the upload")
+	}
+
+	lister, err := client.NewLister(ListConfig{Bucket: "b"})
+	if err != nil {
+		t.Fatalf("NewLister() error = %v", err)
+	}
+	objects, err := lister.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "data.txt" {
+		t.Errorf("List() = %+v, want data.txt", objects)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression modes for Config.Compression.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionAuto = "auto" // gzip for compressible content types, otherwise none
+)
+
+// metaOriginalSize records the uncompressed size of compressed objects.
+const metaOriginalSize = "streamup-original-size"
+
+// resolveCompression returns the encoding to apply for a configuration,
+// or an empty string when the object should be stored as-is.
+func resolveCompression(compression, contentType, contentEncoding, key string) string {
+	if compression != CompressionAuto {
+		return compression
+	}
+
+	// Leave content that is already encoded (e.g. .gz files) alone
+	if contentEncoding != "" || GetContentEncoding(key) != "" {
+		return ""
+	}
+	if contentType == "" {
+		contentType = DetectContentType(key)
+	}
+	if ShouldCompress(contentType) {
+		return CompressionGzip
+	}
+	return ""
+}
+
+// compressReader returns a reader producing the compressed form of r.
+//
+// Compression runs in a goroutine writing into a pipe, so memory use is
+// bounded by the compressor's window. Closing the returned reader stops it.
+func compressReader(r io.Reader, encoding string) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		var w io.WriteCloser
+		var err error
+		switch encoding {
+		case CompressionGzip:
+			w = gzip.NewWriter(pw)
+		case CompressionZstd:
+			w, err = zstd.NewWriter(pw)
+		default:
+			err = fmt.Errorf("unsupported compression %q", encoding)
+		}
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(w, r); err != nil {
+			w.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Close())
+	}()
+
+	return pr
+}
+
+// decompressReader returns a reader producing the decoded form of r for a
+// Content-Encoding, or r itself if the encoding is not one we produce.
+func decompressReader(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
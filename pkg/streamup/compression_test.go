@@ -0,0 +1,115 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResolveCompression(t *testing.T) {
+	tests := []struct {
+		name            string
+		compression     string
+		contentType     string
+		contentEncoding string
+		key             string
+		want            string
+	}{
+		{"disabled", "", "", "", "data.json", ""},
+		{"explicit zstd", CompressionZstd, "", "", "backup.tar", CompressionZstd},
+		{"auto text", CompressionAuto, "", "", "logs/app.log.txt", CompressionGzip},
+		{"auto binary", CompressionAuto, "", "", "photo.jpg", ""},
+		{"auto already compressed", CompressionAuto, "", "", "dump.sql.gz", ""},
+		{"auto explicit content type", CompressionAuto, "application/json", "", "blob", CompressionGzip},
+		{"auto with content encoding", CompressionAuto, "text/plain", "br", "notes.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveCompression(tt.compression, tt.contentType, tt.contentEncoding, tt.key)
+			if got != tt.want {
+				t.Errorf("resolveCompression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressReader_RoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("streamup compresses repetitive text well\n"), 10000)
+
+	for _, encoding := range []string{CompressionGzip, CompressionZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			var read atomic.Int64
+			source := &countingReader{reader: bytes.NewReader(data), count: &read}
+
+			compressed, err := io.ReadAll(compressReader(source, encoding))
+			if err != nil {
+				t.Fatalf("compress: unexpected error = %v", err)
+			}
+			if len(compressed) >= len(data) {
+				t.Errorf("compressed %d bytes to %d, want smaller", len(data), len(compressed))
+			}
+			if read.Load() != int64(len(data)) {
+				t.Errorf("countingReader counted %d bytes, want %d", read.Load(), len(data))
+			}
+
+			r, err := decompressReader(bytes.NewReader(compressed), encoding)
+			if err != nil {
+				t.Fatalf("decompressReader() unexpected error = %v", err)
+			}
+			defer r.Close()
+			decompressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("decompress: unexpected error = %v", err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Error("round trip mismatch")
+			}
+		})
+	}
+}
+
+func TestNew_Compression(t *testing.T) {
+	u, err := New(Config{
+		AccessKeyID:     "test-access-key",
+		SecretAccessKey: "test-secret-key",
+		Bucket:          "test-bucket",
+		Key:             "test-key",
+		FileSize:        100 * 1024 * 1024,
+		Compression:     CompressionGzip,
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error = %v", err)
+	}
+
+	// The compressed size is unknown, so parts follow a growing schedule
+	if u.config.FileSize != UnknownSize {
+		t.Errorf("FileSize = %d, want UnknownSize", u.config.FileSize)
+	}
+	if u.schedule.GrowthInterval == 0 {
+		t.Error("schedule.GrowthInterval = 0, want a growing schedule")
+	}
+
+	h := u.headers()
+	if h.contentEncoding == nil || *h.contentEncoding != CompressionGzip {
+		t.Errorf("Content-Encoding = %v, want gzip", h.contentEncoding)
+	}
+	if got := h.metadata[metaOriginalSize]; got != "104857600" {
+		t.Errorf("metadata[%s] = %q, want 104857600", metaOriginalSize, got)
+	}
+}
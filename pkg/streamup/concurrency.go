@@ -0,0 +1,136 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// throttleCooldown stops one burst of throttling responses, seen by several
+// workers at once, from halving the concurrency more than once.
+const throttleCooldown = time.Second
+
+// concurrencyController limits how many part uploads are in flight and adapts
+// the limit using AIMD: the limit halves when the service throttles us and
+// grows by one after a full window of successful requests.
+type concurrencyController struct {
+	min int
+	max int
+
+	mu           sync.Mutex
+	limit        int
+	inFlight     int
+	successes    int
+	lastDecrease time.Time
+	changed      chan struct{} // Closed (and replaced) when a waiter may proceed
+}
+
+// newConcurrencyController creates a controller starting at max concurrency.
+func newConcurrencyController(min, max int) *concurrencyController {
+	return &concurrencyController{
+		min:     min,
+		max:     max,
+		limit:   max,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire waits for an in-flight slot under the current limit.
+func (c *concurrencyController) acquire(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.inFlight < c.limit {
+			c.inFlight++
+			c.mu.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot and adjusts the limit based on the request outcome.
+func (c *concurrencyController) release(throttled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+
+	if throttled {
+		c.successes = 0
+		if time.Since(c.lastDecrease) >= throttleCooldown {
+			c.limit /= 2
+			if c.limit < c.min {
+				c.limit = c.min
+			}
+			c.lastDecrease = time.Now()
+		}
+	} else {
+		c.successes++
+		if c.successes >= c.limit && c.limit < c.max {
+			c.limit++
+			c.successes = 0
+		}
+	}
+
+	// Wake waiters so they can re-check the limit
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// level returns the current concurrency limit.
+func (c *concurrencyController) level() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// isThrottleError reports whether err means the service wants us to slow down.
+func isThrottleError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "SlowDown", "Throttling", "ThrottlingException", "TooManyRequests", "RequestLimitExceeded":
+			return true
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestConcurrencyController_AIMD(t *testing.T) {
+	c := newConcurrencyController(1, 8)
+	ctx := context.Background()
+
+	// Throttling halves the limit, but only once per cooldown window
+	for i := 0; i < 3; i++ {
+		if err := c.acquire(ctx); err != nil {
+			t.Fatalf("acquire() unexpected error = %v", err)
+		}
+		c.release(true)
+	}
+	if got := c.level(); got != 4 {
+		t.Errorf("level() after throttling = %d, want 4", got)
+	}
+
+	// A full window of successes adds one slot
+	for i := 0; i < 4; i++ {
+		if err := c.acquire(ctx); err != nil {
+			t.Fatalf("acquire() unexpected error = %v", err)
+		}
+		c.release(false)
+	}
+	if got := c.level(); got != 5 {
+		t.Errorf("level() after successes = %d, want 5", got)
+	}
+}
+
+func TestConcurrencyController_MinBound(t *testing.T) {
+	c := newConcurrencyController(2, 4)
+
+	for i := 0; i < 3; i++ {
+		c.lastDecrease = time.Time{} // Skip the cooldown
+		c.inFlight++
+		c.release(true)
+	}
+	if got := c.level(); got != 2 {
+		t.Errorf("level() = %d, want minimum 2", got)
+	}
+}
+
+func TestConcurrencyController_AcquireBlocks(t *testing.T) {
+	c := newConcurrencyController(1, 1)
+	if err := c.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+
+	// The only slot is taken, so the next acquire waits until released
+	done := make(chan error, 1)
+	go func() { done <- c.acquire(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("acquire() returned while no slot was free")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.release(false)
+	if err := <-done; err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+}
+
+func TestIsThrottleError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"SlowDown", &smithy.GenericAPIError{Code: "SlowDown"}, true},
+		{"HTTP 503", &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}},
+			Err:      errors.New("service unavailable"),
+		}, true},
+		{"HTTP 500", &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusInternalServerError}},
+			Err:      errors.New("internal error"),
+		}, false},
+		{"AccessDenied", &smithy.GenericAPIError{Code: "AccessDenied"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isThrottleError(tt.err); got != tt.want {
+				t.Errorf("isThrottleError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
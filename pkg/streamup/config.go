@@ -17,8 +17,17 @@ package streamup
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 )
 
+// UnknownSize can be used as Config.FileSize when the length of the stream
+// cannot be determined up front (e.g. when piping from another process). Part
+// sizes then grow as the upload progresses; see CalculatePartSizeSchedule.
+const UnknownSize int64 = -1
+
 // ProgressCallback is called periodically during upload to report progress.
 // bytesUploaded: total bytes uploaded so far
 // partsUploaded: number of parts successfully uploaded
@@ -26,16 +35,18 @@ type ProgressCallback func(bytesUploaded int64, partsUploaded int32)
 
 // Config holds the configuration for an S3 multipart upload.
 type Config struct {
-	// S3 Credentials
+	// S3 Credentials: static keys, or a provider such as DefaultCredentials
 	AccessKeyID     string
 	SecretAccessKey string
+	SessionToken    string                  // Optional session token for temporary keys
+	Credentials     aws.CredentialsProvider // Optional provider (overrides the static keys)
 
 	// S3 Location
 	Bucket string // S3 bucket name
 	Key    string // Object key (path) in the bucket
 
 	// File Information
-	FileSize int64 // Total file size in bytes (required for optimization)
+	FileSize int64 // Total file size in bytes, or UnknownSize for streams of unknown length
 
 	// Service Configuration
 	AccountID string // Required for Cloudflare R2, ignored for other services
@@ -48,11 +59,49 @@ type Config struct {
 	MaxMemoryMB   int            // Optional memory limit in MB (0 = no limit)
 	ServiceLimits *ServiceLimits // Optional service-specific limits (nil = use S3 defaults)
 
+	// Adaptive concurrency: halve the number of parts in flight when the service
+	// throttles (SlowDown/503) and raise it again while parts succeed
+	AdaptiveConcurrency bool
+	MinWorkers          int // Lower bound for adaptive concurrency (default: 1)
+
+	// Bandwidth limiting
+	MaxBytesPerSecond int64        // Optional upload rate limit shared by all workers (0 = unlimited)
+	RateLimiter       *RateLimiter // Optional limiter shared with other transfers (overrides MaxBytesPerSecond)
+
+	// Objects of up to this many bytes are sent with a single PutObject request
+	// (default: ServiceLimits.MinPartSize, -1 = always use multipart)
+	SinglePartThreshold int64
+
 	// Retry Configuration
 	MaxRetries      int // Maximum retry attempts per part (default: 3)
 	RetryDelay      int // Initial retry delay in milliseconds (default: 1000)
 	MaxRetryDelay   int // Maximum retry delay in milliseconds (default: 30000)
 	RetryMultiplier int // Backoff multiplier (default: 2)
+	RetryBudget     int // Maximum retries across all requests of an upload (default: 0 = unlimited)
+
+	// Optional policy deciding which failed requests are retried and when
+	// (default: a DefaultRetryPolicy built from the settings above)
+	RetryPolicy RetryPolicy
+
+	// Timeout for each attempt at creating, completing or aborting the
+	// multipart upload (default: 5 minutes; completing can take minutes)
+	RequestTimeout time.Duration
+
+	// Total time to spend aborting a failed upload, across all attempts
+	// (default: 1 minute)
+	AbortTimeout time.Duration
+
+	// Fail the upload if the source sends nothing for this long (0 = wait
+	// forever). Seekable sources such as files are not watched.
+	SourceIdleTimeout time.Duration
+
+	// Abort rather than complete the upload when the source is not FileSize
+	// bytes long (default: false, the object is stored and a warning logged)
+	StrictSize bool
+
+	// Straggling parts
+	PartTimeout     time.Duration // Give up and retry a part attempt that takes longer (0 = no timeout)
+	HedgeMultiplier float64       // Send a part again in parallel once it takes this many times the recent median, first copy wins (0 = off)
 
 	// Object Metadata
 	ContentType        string            // MIME type (auto-detected if empty)
@@ -62,13 +111,50 @@ type Config struct {
 	CacheControl       string            // Cache-Control header
 	Metadata           map[string]string // Custom metadata key-value pairs
 
+	// Conditional writes, checked when the upload completes
+	IfNoneMatch bool   // Only create the object if the key does not already exist
+	IfMatch     string // Only replace the object if its current ETag matches
+
+	// Object Management
+	Tags         map[string]string // Object tags (max 10; keys up to 128 chars, values up to 256)
+	StorageClass string            // Storage class (e.g., "STANDARD_IA", "GLACIER_IR", R2 "InfrequentAccess")
+	ACL          string            // Canned ACL (e.g., "private", "bucket-owner-full-control")
+
 	// Progress Tracking
 	ProgressCallback ProgressCallback // Optional callback for progress updates
+	Observer         Observer         // Optional receiver for upload events (see events.go)
+	Logger           *slog.Logger     // Optional logger for lifecycle, retries and aborts (default: the client's, or none)
 
 	// Checksum
 	CalculateChecksum bool   // Calculate checksum during upload (default: true)
 	ChecksumAlgorithm string // Algorithm: "md5", "sha256" (default: "md5")
 
+	// Server-side checksums (S3 additional checksums, verified by the service)
+	ServerChecksumAlgorithm string // Optional: "crc32", "crc32c", "crc64nvme", "sha1", "sha256"
+	ServerChecksumType      string // "composite" or "full_object" (default: full_object for crc64nvme, else composite)
+	SendContentMD5          bool   // Send Content-MD5 with every part (for services without additional checksums)
+
+	// Compression applied before parts are cut: "gzip", "zstd" or "auto" (gzip
+	// when ShouldCompress(DetectContentType(Key))). Sets Content-Encoding; since
+	// the compressed size is unknown up front, parts follow a PartSizeSchedule.
+	Compression string
+
+	// Server-side encryption
+	ServerSideEncryption    string            // Optional: "AES256" (SSE-S3), "aws:kms" or "aws:kms:dsse" (SSE-KMS)
+	SSEKMSKeyID             string            // KMS key ID or ARN (default: the AWS managed key)
+	SSEKMSEncryptionContext map[string]string // Optional KMS encryption context
+	SSECustomerKey          []byte            // 32-byte SSE-C key, sent with every request
+
+	// Client-side encryption (nil = disabled). FileSize is the plaintext size;
+	// checksums and verification apply to the encrypted bytes that are stored.
+	Encryption *EncryptionConfig
+
+	// Verification
+	VerifyUpload bool // HeadObject after upload and compare size and ETag with what was sent
+
+	// Resume
+	ResumeJournal string // Optional journal file path; enables resuming failed uploads
+
 	// Context
 	Context context.Context // Optional context for cancellation (default: background)
 }
@@ -76,11 +162,13 @@ type Config struct {
 // Validate checks if the configuration is valid.
 func (c *Config) Validate() error {
 	// Required fields
-	if c.AccessKeyID == "" {
-		return &ValidationError{Field: "AccessKeyID", Message: "required"}
-	}
-	if c.SecretAccessKey == "" {
-		return &ValidationError{Field: "SecretAccessKey", Message: "required"}
+	if c.Credentials == nil {
+		if c.AccessKeyID == "" {
+			return &ValidationError{Field: "AccessKeyID", Message: "required"}
+		}
+		if c.SecretAccessKey == "" {
+			return &ValidationError{Field: "SecretAccessKey", Message: "required"}
+		}
 	}
 	if c.Bucket == "" {
 		return &ValidationError{Field: "Bucket", Message: "required"}
@@ -88,8 +176,8 @@ func (c *Config) Validate() error {
 	if c.Key == "" {
 		return &ValidationError{Field: "Key", Message: "required"}
 	}
-	if c.FileSize <= 0 {
-		return &ValidationError{Field: "FileSize", Message: "must be greater than 0"}
+	if c.FileSize <= 0 && c.FileSize != UnknownSize {
+		return &ValidationError{Field: "FileSize", Message: "must be greater than 0 (or UnknownSize)"}
 	}
 
 	// Apply defaults
@@ -99,6 +187,40 @@ func (c *Config) Validate() error {
 	if c.QueueSize <= 0 {
 		c.QueueSize = defaultQueueSize
 	}
+	if c.AdaptiveConcurrency {
+		if c.MinWorkers <= 0 {
+			c.MinWorkers = 1
+		}
+		if c.MinWorkers > c.Workers {
+			return &ValidationError{
+				Field:   "MinWorkers",
+				Message: fmt.Sprintf("must not exceed Workers (%d)", c.Workers),
+			}
+		}
+	}
+	switch c.Compression {
+	case "", CompressionAuto:
+	case CompressionGzip, CompressionZstd:
+		if c.ContentEncoding != "" && c.ContentEncoding != c.Compression {
+			return &ValidationError{
+				Field:   "ContentEncoding",
+				Message: fmt.Sprintf("conflicts with Compression %q", c.Compression),
+			}
+		}
+	default:
+		return &ValidationError{Field: "Compression", Message: "must be 'gzip', 'zstd' or 'auto'"}
+	}
+	if err := validateSSE(c.ServerSideEncryption, c.SSEKMSKeyID, c.SSEKMSEncryptionContext, c.SSECustomerKey); err != nil {
+		return err
+	}
+	if c.Encryption != nil {
+		if err := c.Encryption.validate(); err != nil {
+			return err
+		}
+	}
+	if c.MaxBytesPerSecond < 0 {
+		return &ValidationError{Field: "MaxBytesPerSecond", Message: "must not be negative"}
+	}
 
 	// Apply retry defaults
 	if c.MaxRetries <= 0 {
@@ -113,6 +235,30 @@ func (c *Config) Validate() error {
 	if c.RetryMultiplier <= 0 {
 		c.RetryMultiplier = 2 // Default: 2x backoff
 	}
+	if c.RetryBudget < 0 {
+		return &ValidationError{Field: "RetryBudget", Message: "must not be negative"}
+	}
+	if c.RequestTimeout < 0 {
+		return &ValidationError{Field: "RequestTimeout", Message: "must not be negative"}
+	}
+	if c.RequestTimeout == 0 {
+		c.RequestTimeout = 5 * time.Minute // Default: 5 minutes
+	}
+	if c.AbortTimeout < 0 {
+		return &ValidationError{Field: "AbortTimeout", Message: "must not be negative"}
+	}
+	if c.AbortTimeout == 0 {
+		c.AbortTimeout = time.Minute // Default: 1 minute
+	}
+	if c.SourceIdleTimeout < 0 {
+		return &ValidationError{Field: "SourceIdleTimeout", Message: "must not be negative"}
+	}
+	if c.PartTimeout < 0 {
+		return &ValidationError{Field: "PartTimeout", Message: "must not be negative"}
+	}
+	if c.HedgeMultiplier != 0 && c.HedgeMultiplier <= 1 {
+		return &ValidationError{Field: "HedgeMultiplier", Message: "must be greater than 1 (or 0 to disable hedging)"}
+	}
 
 	// Apply checksum defaults
 	if c.ChecksumAlgorithm == "" {
@@ -126,6 +272,23 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate server-side checksum settings
+	if err := c.validateServerChecksum(); err != nil {
+		return err
+	}
+
+	if c.IfNoneMatch && c.IfMatch != "" {
+		return &ValidationError{Field: "IfMatch", Message: "cannot be combined with IfNoneMatch"}
+	}
+
+	// Validate tags and ACL
+	if err := validateTags(c.Tags); err != nil {
+		return err
+	}
+	if c.ACL != "" && !isCannedACL(c.ACL) {
+		return &ValidationError{Field: "ACL", Message: fmt.Sprintf("unknown canned ACL %q", c.ACL)}
+	}
+
 	// Validate or set service limits
 	if c.ServiceLimits == nil {
 		limits := DefaultS3Limits()
@@ -136,9 +299,20 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Apply single-request threshold default
+	if c.SinglePartThreshold == 0 {
+		c.SinglePartThreshold = c.ServiceLimits.MinPartSize
+	}
+	if c.SinglePartThreshold > c.ServiceLimits.MaxPartSize {
+		return &ValidationError{
+			Field:   "SinglePartThreshold",
+			Message: "cannot exceed MaxPartSize",
+		}
+	}
+
 	// Check file size against service limits
 	maxFileSize := c.ServiceLimits.MaxFileSize()
-	if c.FileSize > maxFileSize {
+	if c.FileSize != UnknownSize && c.FileSize > maxFileSize {
 		return &ValidationError{
 			Field: "FileSize",
 			Message: fmt.Sprintf("exceeds service limit of %d bytes (%d GB)",
@@ -168,6 +342,59 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateServerChecksum checks the server-side checksum algorithm and type,
+// applying the default type for the algorithm if none was given.
+func (c *Config) validateServerChecksum() error {
+	if c.ServerChecksumAlgorithm == "" {
+		if c.ServerChecksumType != "" {
+			return &ValidationError{
+				Field:   "ServerChecksumType",
+				Message: "requires ServerChecksumAlgorithm",
+			}
+		}
+		return nil
+	}
+
+	if newServerChecksumHash(c.ServerChecksumAlgorithm) == nil {
+		return &ValidationError{
+			Field:   "ServerChecksumAlgorithm",
+			Message: "must be 'crc32', 'crc32c', 'crc64nvme', 'sha1' or 'sha256'",
+		}
+	}
+
+	// CRC64NVME only supports full object checksums
+	if c.ServerChecksumType == "" {
+		c.ServerChecksumType = ServerChecksumComposite
+		if c.ServerChecksumAlgorithm == ServerChecksumCRC64NVME {
+			c.ServerChecksumType = ServerChecksumFullObject
+		}
+	}
+
+	switch c.ServerChecksumType {
+	case ServerChecksumComposite:
+		if c.ServerChecksumAlgorithm == ServerChecksumCRC64NVME {
+			return &ValidationError{
+				Field:   "ServerChecksumType",
+				Message: "crc64nvme only supports 'full_object'",
+			}
+		}
+	case ServerChecksumFullObject:
+		if c.ServerChecksumAlgorithm == ServerChecksumSHA1 || c.ServerChecksumAlgorithm == ServerChecksumSHA256 {
+			return &ValidationError{
+				Field:   "ServerChecksumType",
+				Message: "'full_object' requires a CRC algorithm",
+			}
+		}
+	default:
+		return &ValidationError{
+			Field:   "ServerChecksumType",
+			Message: "must be 'composite' or 'full_object'",
+		}
+	}
+
+	return nil
+}
+
 // GetEndpoint returns the S3 endpoint URL to use.
 func (c *Config) GetEndpoint() string {
 	if c.Endpoint != "" {
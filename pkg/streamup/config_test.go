@@ -17,6 +17,8 @@ package streamup
 import (
 	"context"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -96,6 +98,16 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:     true,
 			errContains: "SecretAccessKey",
 		},
+		{
+			name: "Credentials provider without static keys",
+			config: Config{
+				Credentials: credentials.NewStaticCredentialsProvider("id", "secret", ""),
+				Bucket:      "test-bucket",
+				Key:         "test-key",
+				FileSize:    100 * 1024 * 1024,
+			},
+			wantErr: false,
+		},
 		{
 			name: "Missing Bucket",
 			config: Config{
@@ -142,6 +154,17 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:     true,
 			errContains: "FileSize",
 		},
+		{
+			name: "Unknown FileSize",
+			config: Config{
+				AccessKeyID:     "test-access-key",
+				SecretAccessKey: "test-secret-key",
+				Bucket:          "test-bucket",
+				Key:             "test-key",
+				FileSize:        UnknownSize,
+			},
+			wantErr: false,
+		},
 		{
 			name: "FileSize exceeds service limits",
 			config: Config{
@@ -154,6 +177,103 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:     true,
 			errContains: "exceeds service limit",
 		},
+		{
+			name: "SinglePartThreshold exceeds MaxPartSize",
+			config: Config{
+				AccessKeyID:         "test-access-key",
+				SecretAccessKey:     "test-secret-key",
+				Bucket:              "test-bucket",
+				Key:                 "test-key",
+				FileSize:            100 * 1024 * 1024,
+				SinglePartThreshold: 6 * 1024 * 1024 * 1024,
+			},
+			wantErr:     true,
+			errContains: "SinglePartThreshold",
+		},
+		{
+			name: "Invalid ServerChecksumAlgorithm",
+			config: Config{
+				AccessKeyID:             "test-access-key",
+				SecretAccessKey:         "test-secret-key",
+				Bucket:                  "test-bucket",
+				Key:                     "test-key",
+				FileSize:                100 * 1024 * 1024,
+				ServerChecksumAlgorithm: "md5",
+			},
+			wantErr:     true,
+			errContains: "ServerChecksumAlgorithm",
+		},
+		{
+			name: "CRC64NVME with composite checksum type",
+			config: Config{
+				AccessKeyID:             "test-access-key",
+				SecretAccessKey:         "test-secret-key",
+				Bucket:                  "test-bucket",
+				Key:                     "test-key",
+				FileSize:                100 * 1024 * 1024,
+				ServerChecksumAlgorithm: "crc64nvme",
+				ServerChecksumType:      "composite",
+			},
+			wantErr:     true,
+			errContains: "full_object",
+		},
+		{
+			name: "SHA256 with full object checksum type",
+			config: Config{
+				AccessKeyID:             "test-access-key",
+				SecretAccessKey:         "test-secret-key",
+				Bucket:                  "test-bucket",
+				Key:                     "test-key",
+				FileSize:                100 * 1024 * 1024,
+				ServerChecksumAlgorithm: "sha256",
+				ServerChecksumType:      "full_object",
+			},
+			wantErr:     true,
+			errContains: "CRC algorithm",
+		},
+		{
+			name: "MinWorkers exceeds Workers",
+			config: Config{
+				AccessKeyID:         "test-access-key",
+				SecretAccessKey:     "test-secret-key",
+				Bucket:              "test-bucket",
+				Key:                 "test-key",
+				FileSize:            100 * 1024 * 1024,
+				Workers:             4,
+				AdaptiveConcurrency: true,
+				MinWorkers:          8,
+			},
+			wantErr:     true,
+			errContains: "MinWorkers",
+		},
+		{
+			name: "Compression conflicts with ContentEncoding",
+			config: Config{
+				AccessKeyID:     "test-access-key",
+				SecretAccessKey: "test-secret-key",
+				Bucket:          "test-bucket",
+				Key:             "test-key",
+				FileSize:        100 * 1024 * 1024,
+				Compression:     CompressionZstd,
+				ContentEncoding: "gzip",
+			},
+			wantErr:     true,
+			errContains: "ContentEncoding",
+		},
+		{
+			name: "IfMatch with IfNoneMatch",
+			config: Config{
+				AccessKeyID:     "test-access-key",
+				SecretAccessKey: "test-secret-key",
+				Bucket:          "test-bucket",
+				Key:             "test-key",
+				FileSize:        100 * 1024 * 1024,
+				IfNoneMatch:     true,
+				IfMatch:         "abc123",
+			},
+			wantErr:     true,
+			errContains: "IfMatch",
+		},
 		{
 			name: "Invalid custom service limits",
 			config: Config{
@@ -230,6 +350,10 @@ func TestConfig_Validate_Defaults(t *testing.T) {
 	if cfg.Context == nil {
 		t.Error("Context is nil, expected background context")
 	}
+
+	if cfg.SinglePartThreshold != defaultMinPartSize {
+		t.Errorf("SinglePartThreshold = %d, want default %d", cfg.SinglePartThreshold, defaultMinPartSize)
+	}
 }
 
 func TestConfig_Validate_R2Endpoint(t *testing.T) {
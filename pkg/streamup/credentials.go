@@ -0,0 +1,53 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// DefaultCredentials returns the SDK's default credential chain: environment
+// variables, shared config and credentials files, SSO, web identity and
+// container or instance roles. A non-empty profile selects a named profile
+// from the shared config files.
+func DefaultCredentials(ctx context.Context, profile string) (aws.CredentialsProvider, error) {
+	var opts []func(*config.LoadOptions) error
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default credentials: %w", err)
+	}
+	if awsCfg.Credentials == nil {
+		return nil, fmt.Errorf("no credentials found in the default credential chain")
+	}
+	return awsCfg.Credentials, nil
+}
+
+// credentialsProvider returns provider if set, otherwise a provider for the
+// static keys and optional session token.
+func credentialsProvider(provider aws.CredentialsProvider, accessKeyID, secretAccessKey, sessionToken string) aws.CredentialsProvider {
+	if provider != nil {
+		return provider
+	}
+	return credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, sessionToken)
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestCredentialsProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("static keys with session token", func(t *testing.T) {
+		creds, err := credentialsProvider(nil, "id", "secret", "token").Retrieve(ctx)
+		if err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+		if creds.AccessKeyID != "id" || creds.SecretAccessKey != "secret" || creds.SessionToken != "token" {
+			t.Errorf("Retrieve() = %+v, want static keys", creds)
+		}
+	})
+
+	t.Run("provider overrides static keys", func(t *testing.T) {
+		provider := credentials.NewStaticCredentialsProvider("provider-id", "provider-secret", "")
+		creds, err := credentialsProvider(provider, "id", "secret", "").Retrieve(ctx)
+		if err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+		if creds.AccessKeyID != "provider-id" {
+			t.Errorf("AccessKeyID = %q, want provider-id", creds.AccessKeyID)
+		}
+	})
+}
+
+func TestNewWithCredentialsProvider(t *testing.T) {
+	provider := credentials.NewStaticCredentialsProvider("id", "secret", "")
+
+	if _, err := NewDownloader(DownloadConfig{Credentials: provider, Bucket: "b", Key: "k"}); err != nil {
+		t.Errorf("NewDownloader() error = %v", err)
+	}
+	if _, err := NewLister(ListConfig{Credentials: provider, Bucket: "b"}); err != nil {
+		t.Errorf("NewLister() error = %v", err)
+	}
+	if _, err := NewDownloader(DownloadConfig{Bucket: "b", Key: "k"}); err == nil {
+		t.Error("NewDownloader() without credentials should fail")
+	}
+}
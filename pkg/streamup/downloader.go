@@ -22,17 +22,21 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // DownloadConfig contains configuration for downloading from S3.
 type DownloadConfig struct {
 	AccessKeyID       string // S3 access key ID
 	SecretAccessKey   string // S3 secret access key
+	SessionToken      string // Optional session token for temporary keys
 	Bucket            string // S3 bucket name
 	Key               string // Object key
 	AccountID         string // Cloudflare R2 account ID (optional)
@@ -40,12 +44,44 @@ type DownloadConfig struct {
 	Region            string // S3 region (default: auto for R2, us-east-1 for others)
 	CalculateChecksum bool   // Calculate checksum during download (default: false)
 	ChecksumAlgorithm string // Algorithm: "md5", "sha256" (default: "md5")
+
+	// VerifyServerChecksum asks the service to return the object's additional
+	// checksum so the SDK validates the body against it (ChecksumMode=ENABLED).
+	VerifyServerChecksum bool
+
+	// Server-side encryption. SSECustomerKey is required to read SSE-C objects;
+	// ServerSideEncryption and SSEKMSKeyID, if set, must match the object's.
+	ServerSideEncryption string
+	SSEKMSKeyID          string
+	SSECustomerKey       []byte
+
+	// Client-side encryption: decrypt objects written with Config.Encryption
+	// (nil = disabled; encrypted objects are then downloaded as stored)
+	Encryption *EncryptionConfig
+
+	// Decompress gzip or zstd objects according to their stored Content-Encoding
+	Decompress bool
+
+	// Credentials overrides the static keys (e.g. DefaultCredentials)
+	Credentials aws.CredentialsProvider
+
+	// Bandwidth limiting
+	MaxBytesPerSecond int64        // Optional download rate limit (0 = unlimited)
+	RateLimiter       *RateLimiter // Optional limiter shared with other transfers (overrides MaxBytesPerSecond)
+
+	// Observer optionally receives download events (see events.go)
+	Observer Observer
+
+	// Logger optionally logs the download (default: the client's, or none)
+	Logger *slog.Logger
 }
 
 // Downloader handles streaming downloads from S3-compatible storage.
 type Downloader struct {
 	config           DownloadConfig
-	s3Client         *s3.Client
+	s3Client         S3API
+	logger           *slog.Logger
+	sse              sseHeaders
 	progressCallback func(downloaded int64)
 	checksum         string
 	checksumHash     hash.Hash
@@ -57,66 +93,70 @@ type DownloadProgressCallback func(downloaded int64)
 // NewDownloader creates a new downloader instance.
 func NewDownloader(cfg DownloadConfig) (*Downloader, error) {
 	// Validate required fields
-	if cfg.AccessKeyID == "" {
-		return nil, fmt.Errorf("AccessKeyID is required")
+	if cfg.Credentials == nil {
+		if cfg.AccessKeyID == "" {
+			return nil, fmt.Errorf("AccessKeyID is required")
+		}
+		if cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("SecretAccessKey is required")
+		}
 	}
-	if cfg.SecretAccessKey == "" {
-		return nil, fmt.Errorf("SecretAccessKey is required")
+
+	client, err := NewClient(ClientConfig{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+		Credentials:     cfg.Credentials,
+		AccountID:       cfg.AccountID,
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Logger:          cfg.Logger,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return client.NewDownloader(cfg)
+}
+
+// NewDownloader creates a Downloader that uses the client's connection. The
+// connection settings in cfg are replaced by the client's.
+func (c *Client) NewDownloader(cfg DownloadConfig) (*Downloader, error) {
+	cfg.Credentials = c.credentials
+	cfg.AccountID = c.config.AccountID
+	cfg.Endpoint = c.config.Endpoint
+	cfg.Region = c.config.Region
+
+	// Validate required fields
 	if cfg.Bucket == "" {
 		return nil, fmt.Errorf("bucket is required")
 	}
 	if cfg.Key == "" {
 		return nil, fmt.Errorf("key is required")
 	}
-
-	// Set default region
-	if cfg.Region == "" {
-		if cfg.AccountID != "" {
-			cfg.Region = "auto" // R2 default
-		} else {
-			cfg.Region = "us-east-1" // S3 default
+	if cfg.MaxBytesPerSecond < 0 {
+		return nil, fmt.Errorf("MaxBytesPerSecond must not be negative")
+	}
+	if cfg.Encryption != nil {
+		if err := cfg.Encryption.validate(); err != nil {
+			return nil, err
 		}
 	}
-
-	// Construct endpoint if not provided
-	if cfg.Endpoint == "" && cfg.AccountID != "" {
-		// Cloudflare R2 endpoint format
-		cfg.Endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID)
+	if err := validateSSE(cfg.ServerSideEncryption, cfg.SSEKMSKeyID, nil, cfg.SSECustomerKey); err != nil {
+		return nil, err
 	}
-
-	// Create AWS credentials
-	ctx := context.Background()
-	creds := credentials.NewStaticCredentialsProvider(
-		cfg.AccessKeyID,
-		cfg.SecretAccessKey,
-		"",
-	)
-
-	// Create AWS config with custom User-Agent
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithCredentialsProvider(creds),
-		config.WithRegion(cfg.Region),
-		config.WithAppID(UserAgent()),
-	)
+	sse, err := newSSEHeaders(cfg.ServerSideEncryption, cfg.SSEKMSKeyID, nil, cfg.SSECustomerKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
+	}
+	if cfg.RateLimiter == nil && cfg.MaxBytesPerSecond > 0 {
+		cfg.RateLimiter = NewRateLimiter(cfg.MaxBytesPerSecond)
 	}
-
-	// Create S3 client
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		if cfg.Endpoint != "" {
-			o.BaseEndpoint = aws.String(cfg.Endpoint)
-		}
-		// Use path-style addressing for R2 and custom endpoints
-		if cfg.Endpoint != "" {
-			o.UsePathStyle = true
-		}
-	})
 
 	return &Downloader{
 		config:   cfg,
-		s3Client: s3Client,
+		s3Client: c.s3Client,
+		logger:   c.logger(cfg.Logger),
+		sse:      sse,
 	}, nil
 }
 
@@ -126,11 +166,15 @@ func (d *Downloader) SetProgressCallback(callback DownloadProgressCallback) {
 }
 
 // GetSize retrieves the size of the object without downloading it.
+// With Decompress set, it returns the original size of compressed objects, or -1 if unknown.
 func (d *Downloader) GetSize(ctx context.Context) (int64, error) {
 	// Use HeadObject to get metadata
 	resp, err := d.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(d.config.Bucket),
-		Key:    aws.String(d.config.Key),
+		Bucket:               aws.String(d.config.Bucket),
+		Key:                  aws.String(d.config.Key),
+		SSECustomerAlgorithm: d.sse.customerAlgorithm,
+		SSECustomerKey:       d.sse.customerKey,
+		SSECustomerKeyMD5:    d.sse.customerKeyMD5,
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get object metadata: %w", err)
@@ -140,11 +184,48 @@ func (d *Downloader) GetSize(ctx context.Context) (int64, error) {
 		return 0, fmt.Errorf("object has no Content-Length")
 	}
 
+	// Report the original size of compressed objects, when it was recorded
+	if d.config.Decompress {
+		switch aws.ToString(resp.ContentEncoding) {
+		case CompressionGzip, CompressionZstd:
+			if size, err := strconv.ParseInt(resp.Metadata[metaOriginalSize], 10, 64); err == nil {
+				return size, nil
+			}
+			return -1, nil
+		}
+	}
+
+	// Report the plaintext size of client-side encrypted objects
+	if d.config.Encryption != nil && isEncrypted(resp.Metadata) {
+		env, err := openEnvelope(d.config.Encryption, resp.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read encryption metadata: %w", err)
+		}
+		return plaintextSize(*resp.ContentLength, env.chunkSize), nil
+	}
+
 	return *resp.ContentLength, nil
 }
 
 // Download streams the object to the provided writer.
 func (d *Downloader) Download(ctx context.Context, writer io.Writer) error {
+	started := time.Now()
+	written, err := d.download(ctx, writer)
+	if err != nil {
+		d.emit(DownloadFailed{Bucket: d.config.Bucket, Key: d.config.Key, Err: err})
+		return err
+	}
+	d.emit(DownloadCompleted{
+		Bucket:   d.config.Bucket,
+		Key:      d.config.Key,
+		Size:     written,
+		Duration: time.Since(started),
+	})
+	return nil
+}
+
+// download streams the object and returns the number of bytes written.
+func (d *Downloader) download(ctx context.Context, writer io.Writer) (int64, error) {
 	// Initialize checksum calculation if enabled
 	if d.config.CalculateChecksum {
 		if d.config.ChecksumAlgorithm == "" || d.config.ChecksumAlgorithm == "md5" {
@@ -155,15 +236,59 @@ func (d *Downloader) Download(ctx context.Context, writer io.Writer) error {
 	}
 
 	// Get the object
-	resp, err := d.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(d.config.Bucket),
-		Key:    aws.String(d.config.Key),
-	})
+	input := &s3.GetObjectInput{
+		Bucket:               aws.String(d.config.Bucket),
+		Key:                  aws.String(d.config.Key),
+		SSECustomerAlgorithm: d.sse.customerAlgorithm,
+		SSECustomerKey:       d.sse.customerKey,
+		SSECustomerKeyMD5:    d.sse.customerKeyMD5,
+	}
+	if d.config.VerifyServerChecksum {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+	resp, err := d.s3Client.GetObject(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to get object: %w", err)
+		return 0, fmt.Errorf("failed to get object: %w", err)
 	}
 	defer resp.Body.Close()
 
+	d.emit(DownloadStarted{
+		Bucket: d.config.Bucket,
+		Key:    d.config.Key,
+		Size:   aws.ToInt64(resp.ContentLength),
+		ETag:   aws.ToString(resp.ETag),
+	})
+
+	if err := d.checkSSE(resp.ServerSideEncryption, aws.ToString(resp.SSEKMSKeyId)); err != nil {
+		return 0, err
+	}
+
+	var body io.Reader = resp.Body
+	if d.config.RateLimiter != nil {
+		body = d.config.RateLimiter.Reader(ctx, body)
+	}
+
+	// Decrypt transparently; refuse plaintext objects so a replaced object is noticed
+	if d.config.Encryption != nil {
+		if !isEncrypted(resp.Metadata) {
+			return 0, fmt.Errorf("object is not client-side encrypted")
+		}
+		env, err := openEnvelope(d.config.Encryption, resp.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt object: %w", err)
+		}
+		body = env.decryptReader(body)
+	}
+
+	if d.config.Decompress {
+		decompressed, err := decompressReader(body, aws.ToString(resp.ContentEncoding))
+		if err != nil {
+			return 0, fmt.Errorf("failed to decompress object: %w", err)
+		}
+		defer decompressed.Close()
+		body = decompressed
+	}
+
 	// Prepare writers (output + optional checksum + optional progress)
 	writers := []io.Writer{writer}
 	if d.checksumHash != nil {
@@ -171,22 +296,15 @@ func (d *Downloader) Download(ctx context.Context, writer io.Writer) error {
 	}
 	multiWriter := io.MultiWriter(writers...)
 
-	// Stream to writer with progress tracking
-	if d.progressCallback != nil {
-		// Wrap writer with progress tracking
-		pw := &progressWriter{
-			writer:   multiWriter,
-			callback: d.progressCallback,
-			written:  0,
-		}
-		_, err = io.Copy(pw, resp.Body)
-	} else {
-		// Direct copy without progress
-		_, err = io.Copy(multiWriter, resp.Body)
+	// Stream to writer with progress tracking (the callback and observer may be nil)
+	pw := &progressWriter{
+		writer:   multiWriter,
+		callback: d.progressCallback,
+		observer: d.config.Observer,
+		written:  0,
 	}
-
-	if err != nil {
-		return fmt.Errorf("failed to download object: %w", err)
+	if _, err = io.Copy(pw, body); err != nil {
+		return 0, fmt.Errorf("failed to download object: %w", err)
 	}
 
 	// Finalize checksum if enabled
@@ -194,6 +312,26 @@ func (d *Downloader) Download(ctx context.Context, writer io.Writer) error {
 		d.checksum = hex.EncodeToString(d.checksumHash.Sum(nil))
 	}
 
+	return pw.written, nil
+}
+
+// emit logs an event and sends it to the observer, if any.
+func (d *Downloader) emit(event Event) {
+	logEvent(d.logger, event)
+	if d.config.Observer != nil {
+		d.config.Observer.OnEvent(event)
+	}
+}
+
+// checkSSE confirms the object is encrypted the way the caller expects.
+func (d *Downloader) checkSSE(sse types.ServerSideEncryption, kmsKeyID string) error {
+	if d.config.ServerSideEncryption != "" && string(sse) != d.config.ServerSideEncryption {
+		return fmt.Errorf("object server-side encryption is %q, expected %q", sse, d.config.ServerSideEncryption)
+	}
+	// S3 reports the key ARN, so accept a configured key ID that matches its suffix
+	if id := d.config.SSEKMSKeyID; id != "" && kmsKeyID != id && !strings.HasSuffix(kmsKeyID, "/"+id) {
+		return fmt.Errorf("object KMS key is %q, expected %q", kmsKeyID, id)
+	}
 	return nil
 }
 
@@ -201,6 +339,7 @@ func (d *Downloader) Download(ctx context.Context, writer io.Writer) error {
 type progressWriter struct {
 	writer   io.Writer
 	callback func(int64)
+	observer Observer
 	written  int64
 }
 
@@ -210,6 +349,9 @@ func (pw *progressWriter) Write(p []byte) (int, error) {
 	if pw.callback != nil {
 		pw.callback(pw.written)
 	}
+	if pw.observer != nil && n > 0 {
+		pw.observer.OnEvent(DownloadProgress{Bytes: int64(n), Downloaded: pw.written})
+	}
 	return n, err
 }
 
@@ -0,0 +1,359 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Object metadata written for client-side encrypted objects.
+const (
+	metaEncryption  = "streamup-encryption"
+	metaWrappedKey  = "streamup-wrapped-key"
+	metaNoncePrefix = "streamup-nonce-prefix"
+	metaChunkSize   = "streamup-chunk-size"
+)
+
+const (
+	// encryptionScheme identifies the on-disk format in object metadata.
+	encryptionScheme = "aes-256-gcm-chunked-v1"
+
+	// defaultEncryptionChunkSize is the plaintext size of each authenticated chunk.
+	defaultEncryptionChunkSize = 64 * 1024
+
+	// maxEncryptionChunkSize bounds the memory a downloaded object can make us allocate.
+	maxEncryptionChunkSize = 16 * 1024 * 1024
+
+	// noncePrefixSize leaves 5 bytes of the 12-byte GCM nonce for the chunk
+	// counter (4 bytes) and the final-chunk flag (1 byte).
+	noncePrefixSize = 7
+
+	// gcmOverhead is the authentication tag added to every chunk.
+	gcmOverhead = 16
+)
+
+// dataKeyAD binds wrapped data keys to this use so they cannot be confused
+// with other ciphertexts produced under the same key-encryption key.
+var dataKeyAD = []byte("streamup data key v1")
+
+// EncryptionConfig enables client-side envelope encryption.
+//
+// Each object is encrypted with a random 256-bit data key using AES-256-GCM in
+// fixed-size authenticated chunks. The data key is wrapped with Key and stored,
+// together with the chunk parameters, in the object's metadata. Chunks carry
+// their position and a final-chunk flag in the nonce, so reordering, dropping
+// or truncating chunks is detected on download.
+type EncryptionConfig struct {
+	Key       []byte // 32-byte key-encryption key (see LoadEncryptionKey)
+	ChunkSize int    // Plaintext bytes per authenticated chunk (default: 64 KiB)
+}
+
+// validate checks the key and applies defaults.
+func (e *EncryptionConfig) validate() error {
+	if len(e.Key) != 32 {
+		return &ValidationError{
+			Field:   "Encryption.Key",
+			Message: fmt.Sprintf("must be 32 bytes, got %d", len(e.Key)),
+		}
+	}
+	if e.ChunkSize == 0 {
+		e.ChunkSize = defaultEncryptionChunkSize
+	}
+	if e.ChunkSize < 0 || e.ChunkSize > maxEncryptionChunkSize {
+		return &ValidationError{
+			Field:   "Encryption.ChunkSize",
+			Message: fmt.Sprintf("must be between 1 and %d bytes", maxEncryptionChunkSize),
+		}
+	}
+	return nil
+}
+
+// LoadEncryptionKey reads a 32-byte key from a file. The file may contain the
+// raw key, or the key encoded as hex or base64.
+func LoadEncryptionKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+	if len(data) == 32 {
+		return data, nil
+	}
+	return parseEncryptionKey(string(data))
+}
+
+// EncryptionKeyFromEnv reads a hex or base64 encoded 32-byte key from an
+// environment variable.
+func EncryptionKeyFromEnv(name string) ([]byte, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return parseEncryptionKey(value)
+}
+
+// parseEncryptionKey decodes a hex or base64 encoded 32-byte key.
+func parseEncryptionKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("encryption key must be 32 bytes (raw, hex or base64)")
+}
+
+// envelope holds the per-object data key and chunk parameters.
+type envelope struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+	chunkSize   int
+	wrappedKey  []byte
+}
+
+// newGCM creates an AES-256-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newEnvelope generates a fresh data key and wraps it with the configured key.
+func newEnvelope(cfg *EncryptionConfig) (*envelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, err
+	}
+
+	kek, err := newGCM(cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, kek.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	wrapped := kek.Seal(nonce, nonce, dataKey, dataKeyAD)
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelope{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		chunkSize:   cfg.ChunkSize,
+		wrappedKey:  wrapped,
+	}, nil
+}
+
+// openEnvelope unwraps the data key stored in an object's metadata.
+func openEnvelope(cfg *EncryptionConfig, metadata map[string]string) (*envelope, error) {
+	if scheme := metadata[metaEncryption]; scheme != encryptionScheme {
+		return nil, fmt.Errorf("unsupported encryption scheme %q", scheme)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(metadata[metaWrappedKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key: %w", err)
+	}
+	noncePrefix, err := base64.StdEncoding.DecodeString(metadata[metaNoncePrefix])
+	if err != nil || len(noncePrefix) != noncePrefixSize {
+		return nil, errors.New("invalid nonce prefix")
+	}
+	chunkSize, err := strconv.Atoi(metadata[metaChunkSize])
+	if err != nil || chunkSize <= 0 || chunkSize > maxEncryptionChunkSize {
+		return nil, fmt.Errorf("invalid chunk size %q", metadata[metaChunkSize])
+	}
+
+	kek, err := newGCM(cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < kek.NonceSize() {
+		return nil, errors.New("invalid wrapped key")
+	}
+	dataKey, err := kek.Open(nil, wrapped[:kek.NonceSize()], wrapped[kek.NonceSize():], dataKeyAD)
+	if err != nil {
+		return nil, errors.New("failed to unwrap data key (wrong encryption key?)")
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelope{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		chunkSize:   chunkSize,
+		wrappedKey:  wrapped,
+	}, nil
+}
+
+// metadata returns the object metadata needed to decrypt the object later.
+func (e *envelope) metadata() map[string]string {
+	return map[string]string{
+		metaEncryption:  encryptionScheme,
+		metaWrappedKey:  base64.StdEncoding.EncodeToString(e.wrappedKey),
+		metaNoncePrefix: base64.StdEncoding.EncodeToString(e.noncePrefix),
+		metaChunkSize:   strconv.Itoa(e.chunkSize),
+	}
+}
+
+// isEncrypted reports whether object metadata marks client-side encryption.
+func isEncrypted(metadata map[string]string) bool {
+	_, ok := metadata[metaEncryption]
+	return ok
+}
+
+// nonce builds the GCM nonce for a chunk: prefix || counter || final flag.
+func (e *envelope) nonce(counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, e.noncePrefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptedSize returns the ciphertext size for plaintextSize bytes.
+// Every object has at least one (possibly empty) final chunk.
+func encryptedSize(plaintextSize int64, chunkSize int) int64 {
+	chunks := plaintextSize / int64(chunkSize)
+	if plaintextSize%int64(chunkSize) != 0 || plaintextSize == 0 {
+		chunks++
+	}
+	return plaintextSize + chunks*gcmOverhead
+}
+
+// plaintextSize returns the plaintext bytes contained in the first
+// ciphertextSize bytes of an encrypted stream.
+func plaintextSize(ciphertextSize int64, chunkSize int) int64 {
+	sealedChunk := int64(chunkSize + gcmOverhead)
+	chunks := (ciphertextSize + sealedChunk - 1) / sealedChunk
+	size := ciphertextSize - chunks*gcmOverhead
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// encryptReader returns a reader producing the encrypted form of r.
+// Only one chunk is held in memory at a time.
+func (e *envelope) encryptReader(r io.Reader) io.Reader {
+	return &chunkReader{
+		envelope: e,
+		src:      bufio.NewReader(r),
+		in:       make([]byte, e.chunkSize),
+		seal:     true,
+	}
+}
+
+// decryptReader returns a reader producing the plaintext of the encrypted stream r.
+func (e *envelope) decryptReader(r io.Reader) io.Reader {
+	return &chunkReader{
+		envelope: e,
+		src:      bufio.NewReader(r),
+		in:       make([]byte, e.chunkSize+gcmOverhead),
+		seal:     false,
+	}
+}
+
+// chunkReader seals or opens a stream one chunk at a time.
+type chunkReader struct {
+	*envelope
+	src     *bufio.Reader
+	in      []byte // Current input chunk
+	out     []byte // Output not yet returned to the caller
+	buf     []byte // Backing storage for out
+	counter uint32
+	seal    bool
+	done    bool
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// next reads and transforms the next chunk. A chunk is final when the input
+// ends with it; peeking one byte ahead tells a full last chunk from a full
+// chunk followed by more data.
+func (r *chunkReader) next() error {
+	n, err := io.ReadFull(r.src, r.in)
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.src.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if !final && r.counter == math.MaxUint32 {
+		return errors.New("encrypted stream has too many chunks")
+	}
+
+	nonce := r.nonce(r.counter, final)
+	if r.seal {
+		r.out = r.aead.Seal(r.buf[:0], nonce, r.in[:n], nil)
+	} else {
+		r.out, err = r.aead.Open(r.buf[:0], nonce, r.in[:n], nil)
+		if err != nil {
+			return fmt.Errorf("chunk %d failed authentication (corrupted, truncated or wrong key)", r.counter)
+		}
+	}
+	r.buf = r.out[:0]
+	r.counter++
+	r.done = final
+	return nil
+}
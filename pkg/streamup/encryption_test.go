@@ -0,0 +1,136 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func testEncryptionConfig(t *testing.T) *EncryptionConfig {
+	t.Helper()
+	cfg := &EncryptionConfig{Key: bytes.Repeat([]byte{0x42}, 32), ChunkSize: 16}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate() unexpected error = %v", err)
+	}
+	return cfg
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	cfg := testEncryptionConfig(t)
+
+	// Cover empty input and sizes around chunk boundaries
+	for _, size := range []int{0, 1, 15, 16, 17, 48, 100} {
+		plaintext := bytes.Repeat([]byte("a"), size)
+
+		env, err := newEnvelope(cfg)
+		if err != nil {
+			t.Fatalf("newEnvelope() unexpected error = %v", err)
+		}
+		ciphertext, err := io.ReadAll(env.encryptReader(bytes.NewReader(plaintext)))
+		if err != nil {
+			t.Fatalf("encrypt %d bytes: unexpected error = %v", size, err)
+		}
+		if want := encryptedSize(int64(size), cfg.ChunkSize); int64(len(ciphertext)) != want {
+			t.Errorf("encrypt %d bytes: got %d bytes, encryptedSize() = %d", size, len(ciphertext), want)
+		}
+		if got := plaintextSize(int64(len(ciphertext)), cfg.ChunkSize); got != int64(size) {
+			t.Errorf("plaintextSize(%d) = %d, want %d", len(ciphertext), got, size)
+		}
+
+		// Decrypt using only what is stored in the object metadata
+		opened, err := openEnvelope(cfg, env.metadata())
+		if err != nil {
+			t.Fatalf("openEnvelope() unexpected error = %v", err)
+		}
+		decrypted, err := io.ReadAll(opened.decryptReader(bytes.NewReader(ciphertext)))
+		if err != nil {
+			t.Fatalf("decrypt %d bytes: unexpected error = %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("decrypt %d bytes: round trip mismatch", size)
+		}
+	}
+}
+
+func TestEnvelope_DetectsTampering(t *testing.T) {
+	cfg := testEncryptionConfig(t)
+	env, err := newEnvelope(cfg)
+	if err != nil {
+		t.Fatalf("newEnvelope() unexpected error = %v", err)
+	}
+	ciphertext, err := io.ReadAll(env.encryptReader(bytes.NewReader(bytes.Repeat([]byte("a"), 40))))
+	if err != nil {
+		t.Fatalf("encrypt: unexpected error = %v", err)
+	}
+
+	sealedChunk := cfg.ChunkSize + gcmOverhead
+	flipped := append([]byte{}, ciphertext...)
+	flipped[3] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated at chunk boundary", ciphertext[:2*sealedChunk]},
+		{"empty", nil},
+		{"flipped bit", flipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := io.ReadAll(env.decryptReader(bytes.NewReader(tt.data))); err == nil {
+				t.Error("decrypt succeeded, want authentication error")
+			}
+		})
+	}
+}
+
+func TestOpenEnvelope_WrongKey(t *testing.T) {
+	env, err := newEnvelope(testEncryptionConfig(t))
+	if err != nil {
+		t.Fatalf("newEnvelope() unexpected error = %v", err)
+	}
+
+	other := &EncryptionConfig{Key: bytes.Repeat([]byte{0x24}, 32)}
+	if _, err := openEnvelope(other, env.metadata()); err == nil {
+		t.Error("openEnvelope() with wrong key succeeded, want error")
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+
+	for _, encoded := range []string{
+		hex.EncodeToString(key),
+		base64.StdEncoding.EncodeToString(key) + "\n",
+	} {
+		got, err := parseEncryptionKey(encoded)
+		if err != nil {
+			t.Errorf("parseEncryptionKey(%q) unexpected error = %v", encoded, err)
+			continue
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("parseEncryptionKey(%q) = %x, want %x", encoded, got, key)
+		}
+	}
+
+	if _, err := parseEncryptionKey("too-short"); err == nil {
+		t.Error("parseEncryptionKey() with short key succeeded, want error")
+	}
+}
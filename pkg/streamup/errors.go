@@ -14,7 +14,19 @@
 
 package streamup
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrSourceStalled is returned (wrapped in an UploadError) when the source
+// sends no data for Config.SourceIdleTimeout.
+var ErrSourceStalled = errors.New("source stalled")
 
 // ValidationError represents an error during configuration validation.
 type ValidationError struct {
@@ -39,3 +51,117 @@ func (e *UploadError) Error() string {
 func (e *UploadError) Unwrap() error {
 	return e.Err
 }
+
+// VerificationError is returned when the stored object does not match the
+// data that was uploaded.
+type VerificationError struct {
+	Result VerificationResult
+}
+
+func (e *VerificationError) Error() string {
+	if !e.Result.SizeMatches() {
+		return fmt.Sprintf("upload verification failed: size is %d bytes, expected %d",
+			e.Result.ActualSize, e.Result.ExpectedSize)
+	}
+	return fmt.Sprintf("upload verification failed: ETag is %s, expected %s",
+		e.Result.ActualETag, e.Result.ExpectedETag)
+}
+
+// PreconditionFailedError is returned when a conditional write (Config.IfNoneMatch
+// or Config.IfMatch) is rejected because the object changed or already exists.
+// The multipart upload is aborted and nothing is written.
+type PreconditionFailedError struct {
+	Key string
+	Err error
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed for %s: %v", e.Key, e.Err)
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return e.Err
+}
+
+// RetryBudgetError is returned when a request fails after the upload has
+// used up its retry budget (Config.RetryBudget).
+type RetryBudgetError struct {
+	Budget int
+	Err    error
+}
+
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("retry budget of %d exhausted: %v", e.Budget, e.Err)
+}
+
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// SizeMismatchError is returned with Config.StrictSize when the source is not
+// the declared FileSize. Actual is the number of bytes read, which for a
+// longer source is where reading stopped.
+type SizeMismatchError struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("source size mismatch: read %d bytes, expected %d", e.Actual, e.Expected)
+}
+
+// TimeoutError is returned when an attempt at a request takes longer than
+// its timeout. It is retried like other transient errors.
+type TimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Operation, e.Timeout)
+}
+
+// OrphanedUploadError is returned when an upload fails and the multipart
+// upload could not be aborted either. Its parts remain (and are billed) until
+// UploadID is aborted, e.g. with Cleanup.
+type OrphanedUploadError struct {
+	Bucket   string
+	Key      string
+	UploadID string
+	Err      error // Why the upload failed
+	AbortErr error // Why the abort failed
+}
+
+func (e *OrphanedUploadError) Error() string {
+	return fmt.Sprintf("%v (abort failed, incomplete upload %s remains: %v)", e.Err, e.UploadID, e.AbortErr)
+}
+
+func (e *OrphanedUploadError) Unwrap() error {
+	return e.Err
+}
+
+// isNoSuchUpload reports whether err says the multipart upload does not exist.
+func isNoSuchUpload(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchUpload"
+}
+
+// isNotFound reports whether err is an HTTP 404 for an object.
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NotFound" || apiErr.ErrorCode() == "NoSuchKey") {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// isPreconditionFailed reports whether err is an HTTP 412 Precondition Failed response.
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusPreconditionFailed
+}
@@ -16,6 +16,7 @@ package streamup
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -229,3 +230,27 @@ func TestUploadError_NilUnwrap(t *testing.T) {
 		t.Errorf("UploadError.Unwrap() with nil Err = %v, want nil", unwrapped)
 	}
 }
+
+func TestPreconditionFailedError(t *testing.T) {
+	inner := &mockAPIError{code: "PreconditionFailed"}
+	err := fmt.Errorf("upload failed: %w", &PreconditionFailedError{Key: "backups/db.tar", Err: inner})
+
+	// Callers (and the CLI exit status) find the typed error through wrapping
+	var precondErr *PreconditionFailedError
+	if !errors.As(err, &precondErr) {
+		t.Fatal("errors.As() did not find PreconditionFailedError")
+	}
+	if precondErr.Key != "backups/db.tar" {
+		t.Errorf("Key = %q, want backups/db.tar", precondErr.Key)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("errors.Is() did not find the wrapped API error")
+	}
+
+	if !isPreconditionFailed(inner) {
+		t.Error("isPreconditionFailed(PreconditionFailed) = false, want true")
+	}
+	if isPreconditionFailed(&mockAPIError{code: "NoSuchKey"}) {
+		t.Error("isPreconditionFailed(NoSuchKey) = true, want false")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import "time"
+
+// Observer receives lifecycle events from uploads and downloads.
+//
+// OnEvent is called synchronously from the goroutine that produced the event,
+// including upload workers, so implementations must be safe for concurrent
+// use and should return quickly.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(event Event)
+
+// OnEvent calls f(event).
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// MultiObserver returns an Observer that passes each event to all of observers
+// in order. Nil observers are skipped.
+func MultiObserver(observers ...Observer) Observer {
+	var list []Observer
+	for _, o := range observers {
+		if o != nil {
+			list = append(list, o)
+		}
+	}
+	return ObserverFunc(func(event Event) {
+		for _, o := range list {
+			o.OnEvent(event)
+		}
+	})
+}
+
+// Event is one of the event types below; use a type switch to tell them apart.
+type Event interface {
+	event()
+}
+
+// UploadStarted is sent once the upload is set up, before any data is sent.
+// UploadID is empty for objects written with a single PutObject.
+type UploadStarted struct {
+	Bucket   string
+	Key      string
+	UploadID string
+	Size     int64 // Bytes to store, or UnknownSize
+	Resumed  bool  // Continuing an upload from the resume journal
+}
+
+// PartStarted is sent when a worker starts uploading a part.
+type PartStarted struct {
+	Part int32
+	Size int64
+}
+
+// PartRetry is sent when a part attempt failed and will be retried after Backoff.
+type PartRetry struct {
+	Part      int32
+	Operation string // UploadPart, or PutObject for a single-request upload
+	Attempt   int    // The failed attempt, starting at 1
+	Err       error
+	Backoff   time.Duration
+}
+
+// PartHedged is sent when a part attempt is slow enough (see
+// Config.HedgeMultiplier) that the part is sent a second time in parallel.
+type PartHedged struct {
+	Part  int32
+	After time.Duration // How long the first copy had been running
+}
+
+// PartCompleted is sent when a part was stored. Duration includes retries.
+type PartCompleted struct {
+	Part     int32
+	Size     int64
+	ETag     string
+	Duration time.Duration
+}
+
+// PartFailed is sent when a part could not be uploaded, after any retries.
+type PartFailed struct {
+	Part     int32
+	Err      error
+	Duration time.Duration
+}
+
+// UploadCompleted is sent when the object has been written.
+type UploadCompleted struct {
+	Bucket   string
+	Key      string
+	UploadID string
+	ETag     string
+	Size     int64 // Bytes stored
+	Parts    int
+	Duration time.Duration
+}
+
+// UploadAborted is sent when an upload that had started fails. Unless it was
+// kept for resuming, its parts were discarded; AbortErr reports a failure to
+// do so, which leaves an incomplete upload behind (see CleanupIncompleteUploads).
+type UploadAborted struct {
+	Bucket    string
+	Key       string
+	UploadID  string
+	Err       error
+	AbortErr  error
+	Resumable bool // Kept in place for resuming with the journal
+}
+
+// DownloadStarted is sent when the object's data starts arriving.
+type DownloadStarted struct {
+	Bucket string
+	Key    string
+	Size   int64 // Stored size of the object
+	ETag   string
+}
+
+// DownloadProgress is sent for each chunk of data written to the destination.
+type DownloadProgress struct {
+	Bytes      int64 // Bytes in this chunk
+	Downloaded int64 // Bytes written so far
+}
+
+// DownloadCompleted is sent when the whole object has been written out.
+type DownloadCompleted struct {
+	Bucket   string
+	Key      string
+	Size     int64 // Bytes written to the destination
+	Duration time.Duration
+}
+
+// DownloadFailed is sent when a download fails.
+type DownloadFailed struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+func (UploadStarted) event()     {}
+func (PartStarted) event()       {}
+func (PartRetry) event()         {}
+func (PartHedged) event()        {}
+func (PartCompleted) event()     {}
+func (PartFailed) event()        {}
+func (UploadCompleted) event()   {}
+func (UploadAborted) event()     {}
+func (DownloadStarted) event()   {}
+func (DownloadProgress) event()  {}
+func (DownloadCompleted) event() {}
+func (DownloadFailed) event()    {}
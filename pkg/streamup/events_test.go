@@ -0,0 +1,222 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+// recorder is an Observer that keeps every event.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) OnEvent(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// count returns the number of events of the same type as sample.
+func (r *recorder) count(sample Event) int {
+	n := 0
+	for _, e := range r.events {
+		if reflect.TypeOf(e) == reflect.TypeOf(sample) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUpload_Events(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.MinPartSize = defaultMinPartSize
+	fake.ThrottlePart(2, 1)
+	data := testData(12 * 1024 * 1024)
+
+	events := &recorder{}
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), Observer: events})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	started, ok := events.events[0].(UploadStarted)
+	if !ok || started.UploadID == "" || started.Size != int64(len(data)) {
+		t.Errorf("first event = %#v, want UploadStarted with the upload ID", events.events[0])
+	}
+	completed, ok := events.events[len(events.events)-1].(UploadCompleted)
+	if !ok {
+		t.Fatalf("last event = %#v, want UploadCompleted", events.events[len(events.events)-1])
+	}
+	obj, _ := fake.Object("test-bucket", "test-key")
+	if completed.UploadID != started.UploadID || completed.Parts != 3 ||
+		completed.Size != int64(len(data)) || completed.ETag != obj.ETag {
+		t.Errorf("UploadCompleted = %#v", completed)
+	}
+
+	if n := events.count(PartStarted{}); n != 3 {
+		t.Errorf("PartStarted events = %d, want 3", n)
+	}
+	if n := events.count(PartCompleted{}); n != 3 {
+		t.Errorf("PartCompleted events = %d, want 3", n)
+	}
+	if n := events.count(PartRetry{}); n != 1 {
+		t.Fatalf("PartRetry events = %d, want 1", n)
+	}
+	for _, e := range events.events {
+		if retry, ok := e.(PartRetry); ok {
+			if retry.Part != 2 || retry.Operation != "UploadPart" || retry.Attempt != 1 || retry.Err == nil || retry.Backoff <= 0 {
+				t.Errorf("PartRetry = %#v", retry)
+			}
+		}
+	}
+}
+
+func TestUpload_EventsSinglePart(t *testing.T) {
+	fake := streamuptest.NewFake()
+	data := testData(1024)
+
+	events := &recorder{}
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), Observer: events})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if len(events.events) != 4 {
+		t.Fatalf("events = %#v, want started, part started, part completed and completed", events.events)
+	}
+	if started := events.events[0].(UploadStarted); started.UploadID != "" {
+		t.Errorf("UploadID = %q, want none for PutObject", started.UploadID)
+	}
+	if part := events.events[2].(PartCompleted); part.Part != 1 || part.Size != int64(len(data)) {
+		t.Errorf("PartCompleted = %#v", part)
+	}
+	if completed := events.events[3].(UploadCompleted); completed.Parts != 1 || completed.ETag == "" {
+		t.Errorf("UploadCompleted = %#v", completed)
+	}
+}
+
+func TestUpload_EventsAborted(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	data := testData(6 * 1024 * 1024)
+
+	events := &recorder{}
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), Observer: events})
+	if err := uploader.Upload(bytes.NewReader(data)); err == nil {
+		t.Fatal("Upload() should fail when CompleteMultipartUpload fails")
+	}
+
+	aborted, ok := events.events[len(events.events)-1].(UploadAborted)
+	if !ok {
+		t.Fatalf("last event = %#v, want UploadAborted", events.events[len(events.events)-1])
+	}
+	if aborted.UploadID == "" || aborted.Err == nil || aborted.AbortErr != nil || aborted.Resumable {
+		t.Errorf("UploadAborted = %#v", aborted)
+	}
+	if events.count(UploadCompleted{}) != 0 {
+		t.Error("a failed upload should not report UploadCompleted")
+	}
+}
+
+func TestUpload_EventsPartFailed(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.MinPartSize = defaultMinPartSize
+	fake.AddFault(streamuptest.Fault{Operation: streamuptest.OpUploadPart, PartNumber: 1, Err: streamuptest.ErrInternal()})
+	data := testData(12 * 1024 * 1024)
+
+	events := &recorder{}
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), MaxRetries: 1, Observer: events})
+	if err := uploader.Upload(bytes.NewReader(data)); err == nil {
+		t.Fatal("Upload() should fail when a part keeps failing")
+	}
+
+	var failed []PartFailed
+	for _, e := range events.events {
+		if f, ok := e.(PartFailed); ok {
+			failed = append(failed, f)
+		}
+	}
+	if len(failed) == 0 || failed[0].Part != 1 || failed[0].Err == nil {
+		t.Errorf("PartFailed events = %#v, want part 1", failed)
+	}
+	if events.count(PartRetry{}) == 0 {
+		t.Error("the failing part should have been retried")
+	}
+}
+
+func TestDownload_Events(t *testing.T) {
+	fake := streamuptest.NewFake()
+	_, err := fake.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String("b"),
+		Key:    aws.String("k"),
+		Body:   bytes.NewReader([]byte("hello")),
+	})
+	if err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	client, err := NewClient(ClientConfig{API: fake})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var events []Event
+	observer := ObserverFunc(func(e Event) { events = append(events, e) })
+
+	downloader, err := client.NewDownloader(DownloadConfig{Bucket: "b", Key: "k", Observer: observer})
+	if err != nil {
+		t.Fatalf("NewDownloader() error = %v", err)
+	}
+	if err := downloader.Download(context.Background(), io.Discard); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("events = %#v", events)
+	}
+	if started, ok := events[0].(DownloadStarted); !ok || started.Size != 5 {
+		t.Errorf("first event = %#v, want DownloadStarted", events[0])
+	}
+	if progress, ok := events[1].(DownloadProgress); !ok || progress.Bytes != 5 || progress.Downloaded != 5 {
+		t.Errorf("second event = %#v, want DownloadProgress", events[1])
+	}
+	if completed, ok := events[2].(DownloadCompleted); !ok || completed.Size != 5 {
+		t.Errorf("third event = %#v, want DownloadCompleted", events[2])
+	}
+
+	events = nil
+	missing, err := client.NewDownloader(DownloadConfig{Bucket: "b", Key: "missing", Observer: observer})
+	if err != nil {
+		t.Fatalf("NewDownloader() error = %v", err)
+	}
+	if err := missing.Download(context.Background(), io.Discard); err == nil {
+		t.Fatal("Download() of a missing object should fail")
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %#v, want DownloadFailed", events)
+	}
+	if failed, ok := events[0].(DownloadFailed); !ok || failed.Err == nil {
+		t.Errorf("event = %#v, want DownloadFailed", events[0])
+	}
+}
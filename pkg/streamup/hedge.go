@@ -0,0 +1,147 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	hedgeWindow     = 32 // Recent parts the median is taken over
+	hedgeMinSamples = 5  // Parts to complete before hedging starts
+)
+
+// partRates keeps the upload speed of recently completed parts, in time per
+// byte so that parts of different sizes compare.
+type partRates struct {
+	mu      sync.Mutex
+	samples []float64 // Ring buffer of nanoseconds per byte
+	next    int
+}
+
+// add records a part of size bytes that took d to upload.
+func (r *partRates) add(size int64, d time.Duration) {
+	if size <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate := float64(d) / float64(size)
+	if len(r.samples) < hedgeWindow {
+		r.samples = append(r.samples, rate)
+		return
+	}
+	r.samples[r.next] = rate
+	r.next = (r.next + 1) % hedgeWindow
+}
+
+// median returns the median time per byte, once there are enough samples.
+func (r *partRates) median() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.samples) < hedgeMinSamples {
+		return 0, false
+	}
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+	return sorted[len(sorted)/2], true
+}
+
+// hedgeDelay returns how long to wait for a part of size bytes before sending
+// it again, or false if the part should not be hedged.
+func (u *Uploader) hedgeDelay(size int64) (time.Duration, bool) {
+	// Both copies must produce the same ETag, whichever S3 keeps
+	if u.config.HedgeMultiplier == 0 || !u.sse.etagIsMD5() {
+		return 0, false
+	}
+	rate, ok := u.partRates.median()
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(u.config.HedgeMultiplier * rate * float64(size)), true
+}
+
+// sendPart uploads a part with send. With hedging enabled, a part that takes
+// much longer than recent parts is sent a second time in parallel and the
+// first copy to succeed wins. S3 keeps whichever copy arrives last, but both
+// carry the same data and so the same ETag.
+func (u *Uploader) sendPart(ctx context.Context, p part, send func(ctx context.Context) (*s3.UploadPartOutput, error)) (*s3.UploadPartOutput, error) {
+	started := time.Now()
+	delay, hedge := u.hedgeDelay(p.size())
+	if !hedge {
+		resp, err := send(ctx)
+		if err == nil {
+			u.partRates.add(p.size(), time.Since(started))
+		}
+		return resp, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp *s3.UploadPartOutput
+		err  error
+	}
+	results := make(chan result, 2)
+	start := func() {
+		go func() {
+			resp, err := send(ctx)
+			results <- result{resp: resp, err: err}
+		}()
+	}
+	start()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	inFlight := 1
+	var winner result
+	for {
+		select {
+		case <-timer.C:
+			u.emit(PartHedged{Part: p.number, After: delay})
+			inFlight++
+			start()
+			continue
+		case r := <-results:
+			inFlight--
+			winner = r
+		}
+		if winner.err == nil || inFlight == 0 {
+			break
+		}
+		// One copy failed while the other is still going: wait for it
+	}
+
+	// The loser may still be reading the part's buffer, which is reused once
+	// this returns, so wait for it to stop
+	cancel()
+	for ; inFlight > 0; inFlight-- {
+		<-results
+	}
+
+	if winner.err == nil {
+		u.partRates.add(p.size(), time.Since(started))
+	}
+	return winner.resp, winner.err
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestPartRates(t *testing.T) {
+	var rates partRates
+	for i := 1; i < hedgeMinSamples; i++ {
+		rates.add(1000, time.Duration(i)*time.Millisecond)
+	}
+	if _, ok := rates.median(); ok {
+		t.Error("median() should wait for enough samples")
+	}
+
+	// The window keeps only recent parts: old slow ones roll out
+	for i := 0; i < hedgeWindow; i++ {
+		rates.add(1000, 10*time.Second)
+	}
+	for i := 0; i < hedgeWindow; i++ {
+		rates.add(2000, 2*time.Millisecond)
+	}
+	if median, ok := rates.median(); !ok || median != float64(time.Microsecond) {
+		t.Errorf("median() = %v, %v, want 1µs per byte", median, ok)
+	}
+}
+
+// stallingPartAPI is a fake S3 whose first attempt at one part never
+// answers, like a request stuck on a bad connection.
+type stallingPartAPI struct {
+	*streamuptest.Fake
+	part    int32
+	stalled atomic.Bool
+}
+
+func (s *stallingPartAPI) UploadPart(ctx context.Context, in *s3.UploadPartInput, opts ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if *in.PartNumber == s.part && s.stalled.CompareAndSwap(false, true) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.Fake.UploadPart(ctx, in, opts...)
+}
+
+// newStallingUploader uploads data in 5 MiB parts, one at a time, to a fake
+// S3 on which part stalls.
+func newStallingUploader(t *testing.T, part int32, cfg Config) (*Uploader, *stallingPartAPI) {
+	t.Helper()
+
+	api := &stallingPartAPI{Fake: streamuptest.NewFake(), part: part}
+	client, err := NewClient(ClientConfig{API: api})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	cfg.Bucket = "test-bucket"
+	cfg.Key = "test-key"
+	cfg.Workers = 1
+	cfg.RetryDelay = 1
+	uploader, err := client.NewUploader(cfg)
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	return uploader, api
+}
+
+func TestUpload_PartTimeout(t *testing.T) {
+	data := testData(3 * 5 * 1024 * 1024)
+	events := &recorder{}
+	uploader, api := newStallingUploader(t, 2, Config{
+		FileSize:    int64(len(data)),
+		PartTimeout: 50 * time.Millisecond,
+		Observer:    events,
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	var retried bool
+	for _, e := range events.events {
+		var timeoutErr *TimeoutError
+		if r, ok := e.(PartRetry); ok && r.Part == 2 && errors.As(r.Err, &timeoutErr) {
+			retried = true
+		}
+	}
+	if !retried {
+		t.Error("the stalled part should be retried after a TimeoutError")
+	}
+	if obj, ok := api.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+		t.Error("stored data does not match the source")
+	}
+}
+
+// stallingPutAPI is a fake S3 whose first PutObject never answers.
+type stallingPutAPI struct {
+	*streamuptest.Fake
+	stalled atomic.Bool
+}
+
+func (s *stallingPutAPI) PutObject(ctx context.Context, in *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if s.stalled.CompareAndSwap(false, true) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.Fake.PutObject(ctx, in, opts...)
+}
+
+func TestUpload_PartTimeoutSinglePart(t *testing.T) {
+	api := &stallingPutAPI{Fake: streamuptest.NewFake()}
+	data := testData(1024)
+	events := &recorder{}
+
+	uploader := newFakeUploader(t, api, Config{
+		FileSize:    int64(len(data)),
+		PartTimeout: 50 * time.Millisecond,
+		Observer:    events,
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	var retried bool
+	for _, e := range events.events {
+		var timeoutErr *TimeoutError
+		if r, ok := e.(PartRetry); ok && errors.As(r.Err, &timeoutErr) {
+			retried = true
+		}
+	}
+	if !retried {
+		t.Error("the stalled PutObject should be retried after a TimeoutError")
+	}
+	if obj, ok := api.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+		t.Error("stored data does not match the source")
+	}
+}
+
+func TestUpload_Hedging(t *testing.T) {
+	data := testData(10 * 5 * 1024 * 1024)
+	events := &recorder{}
+	uploader, api := newStallingUploader(t, 8, Config{
+		FileSize:        int64(len(data)),
+		HedgeMultiplier: 2,
+		Observer:        events,
+	})
+
+	// Without hedging the stalled part would hang until the test times out
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	var hedged bool
+	for _, e := range events.events {
+		if h, ok := e.(PartHedged); ok && h.Part == 8 {
+			hedged = true
+		}
+	}
+	if !hedged {
+		t.Error("the stalled part should have been hedged")
+	}
+	if events.count(PartRetry{}) != 0 {
+		t.Error("the hedged copy should succeed without a retry")
+	}
+	if obj, ok := api.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) || obj.Parts != 10 {
+		t.Error("stored data does not match the source")
+	}
+}
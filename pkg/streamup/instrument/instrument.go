@@ -0,0 +1,139 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instrument exports Prometheus metrics and OpenTelemetry spans for
+// streamup transfers.
+//
+// It is built on streamup's events, so programs that don't import it don't
+// depend on Prometheus or OpenTelemetry:
+//
+//	inst, err := instrument.New(instrument.Options{
+//		Registerer:     prometheus.DefaultRegisterer,
+//		TracerProvider: otel.GetTracerProvider(),
+//	})
+//	cfg.Observer = inst.Observer(ctx)
+//
+// Metrics are shared by all transfers, while Observer is called once per
+// upload or download so that its part spans belong to the right parent.
+//
+// Retries are counted from PartRetry events, so only retried parts and
+// PutObject requests are included. Retries of creating, completing or
+// aborting the multipart upload and of HeadObject emit no events; they are
+// only logged.
+package instrument
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/aws/smithy-go"
+	"github.com/matthewgall/streamup/pkg/streamup"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Options configures instrumentation.
+type Options struct {
+	Registerer     prometheus.Registerer // Registry for metrics (nil = no metrics)
+	TracerProvider trace.TracerProvider  // Provider for spans (nil = no tracing)
+	Namespace      string                // Metric name prefix (default: "streamup")
+}
+
+// Instrumentation records metrics and spans for transfers.
+type Instrumentation struct {
+	metrics *metrics
+	tracer  trace.Tracer
+}
+
+// New creates instrumentation and registers its metrics. Metrics that are
+// already registered, e.g. by an earlier call to New, are reused.
+func New(opts Options) (*Instrumentation, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = "streamup"
+	}
+
+	inst := &Instrumentation{}
+	if opts.Registerer != nil {
+		m, err := newMetrics(opts.Registerer, opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		inst.metrics = m
+	}
+
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	inst.tracer = provider.Tracer("github.com/matthewgall/streamup")
+
+	return inst, nil
+}
+
+// Observer returns an observer for a single upload or download. Its spans are
+// children of the span in ctx, if any.
+func (i *Instrumentation) Observer(ctx context.Context) streamup.Observer {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &transfer{
+		inst:  i,
+		ctx:   ctx,
+		parts: make(map[int32]trace.Span),
+	}
+}
+
+// errorCode returns a low-cardinality label for err: the S3 error code for
+// service errors, or a broad category otherwise.
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Timeout"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return "NetworkError"
+	}
+	return "Unknown"
+}
+
+// transfer turns the events of one transfer into metrics and spans.
+type transfer struct {
+	inst *Instrumentation
+	ctx  context.Context
+
+	mu       sync.Mutex
+	span     trace.Span
+	uploadID string
+	parts    map[int32]trace.Span
+}
+
+// OnEvent implements streamup.Observer.
+func (t *transfer) OnEvent(event streamup.Event) {
+	if m := t.inst.metrics; m != nil {
+		m.record(event)
+	}
+	t.trace(event)
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/matthewgall/streamup/pkg/streamup"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// setup returns instrumentation that records into a fresh registry and span recorder.
+func setup(t *testing.T) (*Instrumentation, *prometheus.Registry, *tracetest.SpanRecorder) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	spans := tracetest.NewSpanRecorder()
+	inst, err := New(Options{
+		Registerer:     reg,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return inst, reg, spans
+}
+
+func TestUpload(t *testing.T) {
+	inst, _, spans := setup(t)
+	fake := streamuptest.NewFake()
+	fake.ThrottlePart(2, 1)
+	data := bytes.Repeat([]byte("streamup"), 12*1024*1024/8)
+
+	client, err := streamup.NewClient(streamup.ClientConfig{API: fake})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	uploader, err := client.NewUploader(streamup.Config{
+		Bucket:        "b",
+		Key:           "k",
+		FileSize:      int64(len(data)),
+		MaxMemoryMB:   1,
+		RetryDelay:    1,
+		MaxRetryDelay: 5,
+		Observer:      inst.Observer(context.Background()),
+	})
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	m := inst.metrics
+	checks := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"bytes", testutil.ToFloat64(m.bytes.WithLabelValues(directionUpload)), float64(len(data))},
+		{"parts", testutil.ToFloat64(m.parts.WithLabelValues(resultCompleted)), 3},
+		{"retries", testutil.ToFloat64(m.retries.WithLabelValues("SlowDown")), 1},
+		{"in flight", testutil.ToFloat64(m.inFlight), 0},
+		{"transfers", testutil.ToFloat64(m.transfers.WithLabelValues(directionUpload, resultCompleted)), 1},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 4 {
+		t.Fatalf("ended %d spans, want 3 parts and the upload", len(ended))
+	}
+	upload := ended[len(ended)-1]
+	if upload.Name() != "streamup.Upload" {
+		t.Fatalf("last span = %s, want streamup.Upload", upload.Name())
+	}
+	retried := 0
+	for _, span := range ended[:3] {
+		if span.Name() != "UploadPart" || span.Parent().SpanID() != upload.SpanContext().SpanID() {
+			t.Errorf("span %s is not a part of the upload", span.Name())
+		}
+		retried += len(span.Events())
+	}
+	if retried != 1 {
+		t.Errorf("part spans recorded %d retries, want 1", retried)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	inst, _, spans := setup(t)
+	fake := streamuptest.NewFake()
+	client, err := streamup.NewClient(streamup.ClientConfig{API: fake})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	uploader, _ := client.NewUploader(streamup.Config{Bucket: "b", Key: "k", FileSize: 5})
+	if err := uploader.Upload(bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	for _, key := range []string{"k", "missing"} {
+		downloader, err := client.NewDownloader(streamup.DownloadConfig{
+			Bucket:   "b",
+			Key:      key,
+			Observer: inst.Observer(context.Background()),
+		})
+		if err != nil {
+			t.Fatalf("NewDownloader() error = %v", err)
+		}
+		_ = downloader.Download(context.Background(), io.Discard)
+	}
+
+	m := inst.metrics
+	if got := testutil.ToFloat64(m.bytes.WithLabelValues(directionDownload)); got != 5 {
+		t.Errorf("bytes = %v, want 5", got)
+	}
+	if got := testutil.ToFloat64(m.transfers.WithLabelValues(directionDownload, resultFailed)); got != 1 {
+		t.Errorf("failed downloads = %v, want 1", got)
+	}
+	if ended := spans.Ended(); len(ended) != 2 || ended[1].Status().Description == "" {
+		t.Errorf("download spans = %v, want one ok and one failed", ended)
+	}
+}
+
+func TestNew_SharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := New(Options{Registerer: reg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	second, err := New(Options{Registerer: reg})
+	if err != nil {
+		t.Fatalf("second New() error = %v", err)
+	}
+	if first.metrics.bytes != second.metrics.bytes {
+		t.Error("second New() should reuse the registered collectors")
+	}
+
+	// Without options there is nothing to record to, but observers still work
+	none, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	none.Observer(context.Background()).OnEvent(streamup.PartStarted{Part: 1})
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{streamuptest.ErrSlowDown(), "SlowDown"},
+		{fmt.Errorf("wrapped: %w", streamuptest.ErrInternal()), "InternalError"},
+		{streamuptest.ErrConnectionReset(), "NetworkError"},
+		{context.DeadlineExceeded, "Timeout"},
+		{context.Canceled, "Canceled"},
+		{errors.New("boom"), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"errors"
+
+	"github.com/matthewgall/streamup/pkg/streamup"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Label values for the direction and result labels.
+const (
+	directionUpload   = "upload"
+	directionDownload = "download"
+
+	resultCompleted = "completed"
+	resultFailed    = "failed"
+)
+
+// metrics holds the Prometheus collectors shared by all transfers.
+type metrics struct {
+	bytes        *prometheus.CounterVec   // direction
+	transfers    *prometheus.CounterVec   // direction, result
+	parts        *prometheus.CounterVec   // result
+	retries      *prometheus.CounterVec   // code
+	partDuration *prometheus.HistogramVec // result
+	inFlight     prometheus.Gauge
+	hedges       prometheus.Counter
+}
+
+// newMetrics creates the collectors and registers them with reg.
+func newMetrics(reg prometheus.Registerer, namespace string) (*metrics, error) {
+	m := &metrics{
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bytes_total",
+			Help:      "Bytes transferred, by direction.",
+		}, []string{"direction"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Finished uploads and downloads, by direction and result.",
+		}, []string{"direction", "result"}),
+		parts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "parts_total",
+			Help:      "Uploaded parts, by result.",
+		}, []string{"result"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "part_retries_total",
+			Help:      "Retried part uploads, by the S3 error code of the failed attempt.",
+		}, []string{"code"}),
+		partDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "part_duration_seconds",
+			Help:      "Time to upload a part, including retries.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to ~100s
+		}, []string{"result"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "parts_in_flight",
+			Help:      "Parts currently being uploaded.",
+		}),
+		hedges: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "part_hedges_total",
+			Help:      "Slow parts that were sent a second time in parallel.",
+		}),
+	}
+
+	var err error
+	m.bytes = register(reg, m.bytes, &err)
+	m.transfers = register(reg, m.transfers, &err)
+	m.parts = register(reg, m.parts, &err)
+	m.retries = register(reg, m.retries, &err)
+	m.partDuration = register(reg, m.partDuration, &err)
+	m.inFlight = register(reg, m.inFlight, &err)
+	m.hedges = register(reg, m.hedges, &err)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers c, returning the existing collector if an identical one
+// is already registered. Other errors are stored in *errp.
+func register[C prometheus.Collector](reg prometheus.Registerer, c C, errp *error) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+
+	var exists prometheus.AlreadyRegisteredError
+	if errors.As(err, &exists) {
+		if existing, ok := exists.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	if *errp == nil {
+		*errp = err
+	}
+	return c
+}
+
+// record updates the metrics for an event.
+func (m *metrics) record(event streamup.Event) {
+	switch e := event.(type) {
+	case streamup.PartStarted:
+		m.inFlight.Inc()
+	case streamup.PartRetry:
+		m.retries.WithLabelValues(errorCode(e.Err)).Inc()
+	case streamup.PartHedged:
+		m.hedges.Inc()
+	case streamup.PartCompleted:
+		m.inFlight.Dec()
+		m.bytes.WithLabelValues(directionUpload).Add(float64(e.Size))
+		m.parts.WithLabelValues(resultCompleted).Inc()
+		m.partDuration.WithLabelValues(resultCompleted).Observe(e.Duration.Seconds())
+	case streamup.PartFailed:
+		m.inFlight.Dec()
+		m.parts.WithLabelValues(resultFailed).Inc()
+		m.partDuration.WithLabelValues(resultFailed).Observe(e.Duration.Seconds())
+	case streamup.UploadCompleted:
+		m.transfers.WithLabelValues(directionUpload, resultCompleted).Inc()
+	case streamup.UploadAborted:
+		m.transfers.WithLabelValues(directionUpload, resultFailed).Inc()
+	case streamup.DownloadProgress:
+		m.bytes.WithLabelValues(directionDownload).Add(float64(e.Bytes))
+	case streamup.DownloadCompleted:
+		m.transfers.WithLabelValues(directionDownload, resultCompleted).Inc()
+	case streamup.DownloadFailed:
+		m.transfers.WithLabelValues(directionDownload, resultFailed).Inc()
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"context"
+
+	"github.com/matthewgall/streamup/pkg/streamup"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attributes, following the OpenTelemetry conventions for S3 where they exist.
+const (
+	attrBucket     = attribute.Key("aws.s3.bucket")
+	attrKey        = attribute.Key("aws.s3.key")
+	attrUploadID   = attribute.Key("aws.s3.upload_id")
+	attrPartNumber = attribute.Key("aws.s3.part_number")
+	attrSize       = attribute.Key("streamup.size")
+	attrETag       = attribute.Key("streamup.etag")
+	attrParts      = attribute.Key("streamup.parts")
+	attrResumed    = attribute.Key("streamup.resumed")
+	attrAttempt    = attribute.Key("streamup.attempt")
+	attrErrorCode  = attribute.Key("streamup.error_code")
+	attrBackoff    = attribute.Key("streamup.backoff_ms")
+	attrHedgeAfter = attribute.Key("streamup.hedge_after_ms")
+	attrStage      = attribute.Key("streamup.stage")
+)
+
+// trace starts and ends spans for an event: one span for the transfer and a
+// child span for each part.
+func (t *transfer) trace(event streamup.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e := event.(type) {
+	case streamup.UploadStarted:
+		t.uploadID = e.UploadID
+		_, t.span = t.inst.tracer.Start(t.ctx, "streamup.Upload", trace.WithAttributes(
+			attrBucket.String(e.Bucket),
+			attrKey.String(e.Key),
+			attrUploadID.String(e.UploadID),
+			attrSize.Int64(e.Size),
+			attrResumed.Bool(e.Resumed),
+		))
+
+	case streamup.PartStarted:
+		// Small objects are a single PutObject rather than a part of an upload
+		name := "UploadPart"
+		if t.uploadID == "" {
+			name = "PutObject"
+		}
+		_, span := t.inst.tracer.Start(t.parentContext(), name, trace.WithAttributes(
+			attrPartNumber.Int(int(e.Part)),
+			attrSize.Int64(e.Size),
+		))
+		t.parts[e.Part] = span
+
+	case streamup.PartRetry:
+		if span, ok := t.parts[e.Part]; ok {
+			span.RecordError(e.Err, trace.WithAttributes(
+				attrAttempt.Int(e.Attempt),
+				attrErrorCode.String(errorCode(e.Err)),
+				attrBackoff.Int64(e.Backoff.Milliseconds()),
+			))
+		}
+
+	case streamup.PartHedged:
+		if span, ok := t.parts[e.Part]; ok {
+			span.AddEvent("hedged", trace.WithAttributes(attrHedgeAfter.Int64(e.After.Milliseconds())))
+		}
+
+	case streamup.PartCompleted:
+		if span, ok := t.parts[e.Part]; ok {
+			span.SetAttributes(attrETag.String(e.ETag))
+			span.End()
+			delete(t.parts, e.Part)
+		}
+
+	case streamup.PartFailed:
+		if span, ok := t.parts[e.Part]; ok {
+			fail(span, e.Err)
+			delete(t.parts, e.Part)
+		}
+
+	case streamup.UploadCompleted:
+		if t.span != nil {
+			t.span.SetAttributes(
+				attrETag.String(e.ETag),
+				attrParts.Int(e.Parts),
+				attrSize.Int64(e.Size),
+			)
+			t.span.End()
+		}
+
+	case streamup.UploadAborted:
+		if t.span != nil {
+			if e.AbortErr != nil {
+				t.span.RecordError(e.AbortErr, trace.WithAttributes(attrStage.String("abort")))
+			}
+			fail(t.span, e.Err)
+		}
+
+	case streamup.DownloadStarted:
+		_, t.span = t.inst.tracer.Start(t.ctx, "streamup.Download", trace.WithAttributes(
+			attrBucket.String(e.Bucket),
+			attrKey.String(e.Key),
+			attrSize.Int64(e.Size),
+			attrETag.String(e.ETag),
+		))
+
+	case streamup.DownloadCompleted:
+		if t.span != nil {
+			t.span.SetAttributes(attrSize.Int64(e.Size))
+			t.span.End()
+		}
+
+	case streamup.DownloadFailed:
+		// The request itself may have failed before any data arrived
+		if t.span == nil {
+			_, t.span = t.inst.tracer.Start(t.ctx, "streamup.Download", trace.WithAttributes(
+				attrBucket.String(e.Bucket),
+				attrKey.String(e.Key),
+			))
+		}
+		fail(t.span, e.Err)
+	}
+}
+
+// parentContext returns the context for part spans.
+func (t *transfer) parentContext() context.Context {
+	if t.span == nil {
+		return t.ctx
+	}
+	return trace.ContextWithSpan(t.ctx, t.span)
+}
+
+// fail records err on span and ends it.
+func fail(span trace.Span, err error) {
+	span.RecordError(err, trace.WithAttributes(attrErrorCode.String(errorCode(err))))
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
+}
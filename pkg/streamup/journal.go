@@ -0,0 +1,199 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// journalVersion is bumped whenever the journal format changes incompatibly.
+const journalVersion = 1
+
+// journalHeader is the first line of a resume journal and identifies the
+// multipart upload it belongs to.
+type journalHeader struct {
+	Version         int    `json:"version"`
+	Bucket          string `json:"bucket"`
+	Key             string `json:"key"`
+	UploadID        string `json:"upload_id"`
+	FileSize        int64  `json:"file_size"`
+	InitialPartSize int64  `json:"initial_part_size"`
+	MaxPartSize     int64  `json:"max_part_size"`
+	GrowthInterval  int    `json:"growth_interval"`
+
+	// Encryption holds the wrapped data key of client-side encrypted uploads,
+	// so resumed parts are encrypted with the same key.
+	Encryption map[string]string `json:"encryption,omitempty"`
+}
+
+// journalPart records a single part that was uploaded successfully.
+type journalPart struct {
+	Number   int32  `json:"part"`
+	ETag     string `json:"etag"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum,omitempty"` // Server checksum, if enabled
+}
+
+// resumeJournal is an append-only record of a multipart upload's progress.
+//
+// The file holds one JSON object per line: the header first, followed by one
+// line per completed part. Appending keeps writes small regardless of how many
+// parts have been uploaded, and a torn final line from a crash is ignored.
+type resumeJournal struct {
+	path   string
+	header journalHeader
+	parts  []journalPart
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// loadJournal reads an existing journal. It returns nil if the file does not exist.
+func loadJournal(path string) (*resumeJournal, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	j := &resumeJournal{path: path}
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("journal %s is empty", path)
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &j.header); err != nil {
+		return nil, fmt.Errorf("journal %s has an invalid header: %w", path, err)
+	}
+	if j.header.Version != journalVersion {
+		return nil, fmt.Errorf("journal %s has unsupported version %d", path, j.header.Version)
+	}
+
+	for scanner.Scan() {
+		var p journalPart
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			// A torn final line means we crashed mid-write; that part will be re-uploaded
+			break
+		}
+		j.parts = append(j.parts, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// createJournal writes a fresh journal containing the header and any parts
+// already known to be complete, then opens it for appending.
+func createJournal(path string, header journalHeader, parts []journalPart) (*resumeJournal, error) {
+	header.Version = journalVersion
+
+	// Write to a temporary file and rename so a crash never leaves a half-written header
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	for _, p := range parts {
+		if err := enc.Encode(p); err != nil {
+			tmp.Close()
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resumeJournal{
+		path:   path,
+		header: header,
+		parts:  parts,
+		file:   f,
+	}, nil
+}
+
+// schedule returns the part size schedule the journaled upload was started with.
+func (j *resumeJournal) schedule() PartSizeSchedule {
+	return PartSizeSchedule{
+		InitialSize:    j.header.InitialPartSize,
+		MaxSize:        j.header.MaxPartSize,
+		GrowthInterval: j.header.GrowthInterval,
+	}
+}
+
+// append records a completed part and flushes it to disk.
+func (j *resumeJournal) append(p journalPart) error {
+	line, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	j.parts = append(j.parts, p)
+	return j.file.Sync()
+}
+
+// close closes the journal, leaving it on disk so the upload can be resumed.
+func (j *resumeJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// remove closes and deletes the journal once the upload has completed.
+func (j *resumeJournal) remove() error {
+	_ = j.close()
+	return os.Remove(j.path)
+}
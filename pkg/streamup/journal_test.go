@@ -0,0 +1,243 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestJournal_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.journal")
+
+	header := journalHeader{
+		Bucket:          "test-bucket",
+		Key:             "test-key",
+		UploadID:        "upload-123",
+		FileSize:        UnknownSize,
+		InitialPartSize: 5 * 1024 * 1024,
+		MaxPartSize:     5 * 1024 * 1024 * 1024,
+		GrowthInterval:  909,
+	}
+
+	j, err := createJournal(path, header, []journalPart{{Number: 1, ETag: `"etag1"`, Size: 100}})
+	if err != nil {
+		t.Fatalf("createJournal() unexpected error = %v", err)
+	}
+	if err := j.append(journalPart{Number: 3, ETag: `"etag3"`, Size: 300}); err != nil {
+		t.Fatalf("append() unexpected error = %v", err)
+	}
+	if err := j.close(); err != nil {
+		t.Fatalf("close() unexpected error = %v", err)
+	}
+
+	loaded, err := loadJournal(path)
+	if err != nil {
+		t.Fatalf("loadJournal() unexpected error = %v", err)
+	}
+
+	if loaded.header.UploadID != "upload-123" {
+		t.Errorf("header.UploadID = %q, want %q", loaded.header.UploadID, "upload-123")
+	}
+	if loaded.header.Version != journalVersion {
+		t.Errorf("header.Version = %d, want %d", loaded.header.Version, journalVersion)
+	}
+	if got := loaded.schedule(); got.GrowthInterval != 909 || got.InitialSize != 5*1024*1024 {
+		t.Errorf("schedule() = %+v, want values from header", got)
+	}
+
+	if len(loaded.parts) != 2 {
+		t.Fatalf("loaded %d parts, want 2", len(loaded.parts))
+	}
+	if loaded.parts[1].Number != 3 || loaded.parts[1].ETag != `"etag3"` || loaded.parts[1].Size != 300 {
+		t.Errorf("parts[1] = %+v, want part 3", loaded.parts[1])
+	}
+}
+
+func TestJournal_TornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.journal")
+
+	j, err := createJournal(path, journalHeader{Bucket: "b", Key: "k", UploadID: "u"}, nil)
+	if err != nil {
+		t.Fatalf("createJournal() unexpected error = %v", err)
+	}
+	if err := j.append(journalPart{Number: 1, ETag: "etag1", Size: 10}); err != nil {
+		t.Fatalf("append() unexpected error = %v", err)
+	}
+	j.close()
+
+	// Simulate a crash halfway through writing the next part
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"part":2,"etag":"et`)
+	f.Close()
+
+	loaded, err := loadJournal(path)
+	if err != nil {
+		t.Fatalf("loadJournal() unexpected error = %v", err)
+	}
+	if len(loaded.parts) != 1 {
+		t.Errorf("loaded %d parts, want 1 (torn line ignored)", len(loaded.parts))
+	}
+}
+
+func TestJournal_Missing(t *testing.T) {
+	j, err := loadJournal(filepath.Join(t.TempDir(), "missing.journal"))
+	if err != nil {
+		t.Errorf("loadJournal() unexpected error = %v", err)
+	}
+	if j != nil {
+		t.Error("loadJournal() returned a journal for a missing file")
+	}
+}
+
+func TestJournal_Remove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.journal")
+
+	j, err := createJournal(path, journalHeader{Bucket: "b", Key: "k", UploadID: "u"}, nil)
+	if err != nil {
+		t.Fatalf("createJournal() unexpected error = %v", err)
+	}
+	if err := j.remove(); err != nil {
+		t.Fatalf("remove() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal still exists after remove()")
+	}
+}
+
+func TestStartResumableUpload_DifferentObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.journal")
+
+	j, err := createJournal(path, journalHeader{
+		Bucket:   "other-bucket",
+		Key:      "test-key",
+		UploadID: "upload-123",
+		FileSize: 100 * 1024 * 1024,
+	}, nil)
+	if err != nil {
+		t.Fatalf("createJournal() unexpected error = %v", err)
+	}
+	j.close()
+
+	uploader, err := New(Config{
+		AccessKeyID:     "test-access-key",
+		SecretAccessKey: "test-secret-key",
+		Bucket:          "test-bucket",
+		Key:             "test-key",
+		FileSize:        100 * 1024 * 1024,
+		ResumeJournal:   path,
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error = %v", err)
+	}
+
+	err = uploader.startResumableUpload()
+	if err == nil {
+		t.Fatal("startResumableUpload() expected error for journal of a different object")
+	}
+	if !contains(err.Error(), "different upload") {
+		t.Errorf("startResumableUpload() error = %v, want 'different upload'", err)
+	}
+}
+
+func TestCollectResults_IncludesResumedParts(t *testing.T) {
+	uploader, err := New(Config{
+		AccessKeyID:     "test-access-key",
+		SecretAccessKey: "test-secret-key",
+		Bucket:          "test-bucket",
+		Key:             "test-key",
+		FileSize:        100 * 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error = %v", err)
+	}
+
+	uploader.resumed = map[int32]journalPart{
+		1: {Number: 1, ETag: "etag1", Size: 10},
+		2: {Number: 2, ETag: "etag2", Size: 10},
+	}
+
+	resultsChan := make(chan completedPart, 1)
+	resultsChan <- completedPart{number: 3, etag: "etag3", size: 10}
+	close(resultsChan)
+
+	parts, err := uploader.collectResults(resultsChan)
+	if err != nil {
+		t.Fatalf("collectResults() unexpected error = %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("collectResults() returned %d parts, want 3", len(parts))
+	}
+	for i, p := range parts {
+		if *p.PartNumber != int32(i+1) {
+			t.Errorf("parts[%d].PartNumber = %d, want %d", i, *p.PartNumber, i+1)
+		}
+	}
+}
+
+func TestUpload_ResumeAfterFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.journal")
+	fake := streamuptest.NewFake()
+	fake.MinPartSize = defaultMinPartSize
+	data := testData(4 * 5 * 1024 * 1024)
+	cfg := Config{FileSize: int64(len(data)), Workers: 1, ResumeJournal: path}
+
+	// Part 3 fails for good, leaving parts 1 and 2 journaled
+	fake.AddFault(streamuptest.Fault{
+		Operation:  streamuptest.OpUploadPart,
+		PartNumber: 3,
+		Err:        streamuptest.APIError(http.StatusForbidden, "AccessDenied", "Access Denied"),
+	})
+	if err := newFakeUploader(t, fake, cfg).Upload(bytes.NewReader(data)); err == nil {
+		t.Fatal("Upload() expected error from part 3")
+	}
+	if len(fake.Uploads()) != 1 {
+		t.Fatalf("Uploads() = %v, want the failed upload left in place", fake.Uploads())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("journal missing after failed upload: %v", err)
+	}
+	firstRun := fake.Calls(streamuptest.OpUploadPart)
+
+	fake.ClearFaults()
+	if err := newFakeUploader(t, fake, cfg).Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("resumed Upload() error = %v", err)
+	}
+
+	if got := fake.Calls(streamuptest.OpUploadPart) - firstRun; got != 2 {
+		t.Errorf("resumed run sent %d parts, want 2 (parts 3 and 4)", got)
+	}
+	if fake.Calls(streamuptest.OpCreateMultipartUpload) != 1 {
+		t.Error("resumed run should continue the existing upload")
+	}
+	obj, ok := fake.Object("test-bucket", "test-key")
+	if !ok || !bytes.Equal(obj.Data, data) {
+		t.Fatal("stored data does not match the source")
+	}
+	if obj.Parts != 4 {
+		t.Errorf("Parts = %d, want 4", obj.Parts)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal should be deleted after the upload completes, stat error = %v", err)
+	}
+}
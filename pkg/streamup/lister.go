@@ -17,11 +17,10 @@ package streamup
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -29,12 +28,19 @@ import (
 type ListConfig struct {
 	AccessKeyID     string // S3 access key ID
 	SecretAccessKey string // S3 secret access key
+	SessionToken    string // Optional session token for temporary keys
 	Bucket          string // S3 bucket name
 	AccountID       string // Cloudflare R2 account ID (optional)
 	Endpoint        string // Custom S3 endpoint (optional)
 	Region          string // S3 region (default: auto for R2, us-east-1 for others)
 	Prefix          string // Filter by prefix (optional)
 	MaxKeys         int    // Maximum keys to return (default: 1000)
+
+	// Credentials overrides the static keys (e.g. DefaultCredentials)
+	Credentials aws.CredentialsProvider
+
+	// Logger optionally logs the listing (default: the client's, or none)
+	Logger *slog.Logger
 }
 
 // Object represents an S3 object with metadata.
@@ -47,74 +53,60 @@ type Object struct {
 // Lister handles listing objects in S3-compatible storage.
 type Lister struct {
 	config   ListConfig
-	s3Client *s3.Client
+	s3Client S3API
+	logger   *slog.Logger
 }
 
 // NewLister creates a new lister instance.
 func NewLister(cfg ListConfig) (*Lister, error) {
 	// Validate required fields
-	if cfg.AccessKeyID == "" {
-		return nil, fmt.Errorf("AccessKeyID is required")
+	if cfg.Credentials == nil {
+		if cfg.AccessKeyID == "" {
+			return nil, fmt.Errorf("AccessKeyID is required")
+		}
+		if cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("SecretAccessKey is required")
+		}
 	}
-	if cfg.SecretAccessKey == "" {
-		return nil, fmt.Errorf("SecretAccessKey is required")
+
+	client, err := NewClient(ClientConfig{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+		Credentials:     cfg.Credentials,
+		AccountID:       cfg.AccountID,
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Logger:          cfg.Logger,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return client.NewLister(cfg)
+}
+
+// NewLister creates a Lister that uses the client's connection. The
+// connection settings in cfg are replaced by the client's.
+func (c *Client) NewLister(cfg ListConfig) (*Lister, error) {
+	cfg.Credentials = c.credentials
+	cfg.AccountID = c.config.AccountID
+	cfg.Endpoint = c.config.Endpoint
+	cfg.Region = c.config.Region
+
+	// Validate required fields
 	if cfg.Bucket == "" {
 		return nil, fmt.Errorf("bucket is required")
 	}
 
-	// Set default region
-	if cfg.Region == "" {
-		if cfg.AccountID != "" {
-			cfg.Region = "auto" // R2 default
-		} else {
-			cfg.Region = "us-east-1" // S3 default
-		}
-	}
-
 	// Set default max keys
 	if cfg.MaxKeys == 0 {
 		cfg.MaxKeys = 1000
 	}
 
-	// Construct endpoint if not provided
-	if cfg.Endpoint == "" && cfg.AccountID != "" {
-		// Cloudflare R2 endpoint format
-		cfg.Endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID)
-	}
-
-	// Create AWS credentials
-	ctx := context.Background()
-	creds := credentials.NewStaticCredentialsProvider(
-		cfg.AccessKeyID,
-		cfg.SecretAccessKey,
-		"",
-	)
-
-	// Create AWS config with custom User-Agent
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithCredentialsProvider(creds),
-		config.WithRegion(cfg.Region),
-		config.WithAppID(UserAgent()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
-	}
-
-	// Create S3 client
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		if cfg.Endpoint != "" {
-			o.BaseEndpoint = aws.String(cfg.Endpoint)
-		}
-		// Use path-style addressing for R2 and custom endpoints
-		if cfg.Endpoint != "" {
-			o.UsePathStyle = true
-		}
-	})
-
 	return &Lister{
 		config:   cfg,
-		s3Client: s3Client,
+		s3Client: c.s3Client,
+		logger:   c.logger(cfg.Logger),
 	}, nil
 }
 
@@ -140,6 +132,7 @@ func (l *Lister) List(ctx context.Context) ([]Object, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to list objects: %w", err)
 		}
+		l.logger.Debug("listed objects", "bucket", l.config.Bucket, "prefix", l.config.Prefix, "count", len(page.Contents))
 
 		// Convert to our Object type
 		for _, obj := range page.Contents {
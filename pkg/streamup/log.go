@@ -0,0 +1,142 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// discardLogger is used when no logger is configured.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// loggerOrDiscard returns logger, or a logger that drops everything if it is nil.
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+	return logger
+}
+
+// errorAttrs returns log attributes for err: the error and, for service
+// errors, the S3 error code, HTTP status and request IDs.
+func errorAttrs(err error) []any {
+	attrs := []any{slog.Any("error", err)}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs, slog.String("error_code", apiErr.ErrorCode()))
+	}
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		attrs = append(attrs, slog.Int("status", respErr.HTTPStatusCode()))
+	}
+	var requestID interface{ ServiceRequestID() string }
+	if errors.As(err, &requestID) && requestID.ServiceRequestID() != "" {
+		attrs = append(attrs, slog.String("request_id", requestID.ServiceRequestID()))
+	}
+	var hostID interface{ ServiceHostID() string }
+	if errors.As(err, &hostID) && hostID.ServiceHostID() != "" {
+		attrs = append(attrs, slog.String("host_id", hostID.ServiceHostID()))
+	}
+
+	return attrs
+}
+
+// logEvent logs an upload or download event. Part progress is logged at
+// debug level; download progress is not logged.
+func logEvent(logger *slog.Logger, event Event) {
+	switch e := event.(type) {
+	case UploadStarted:
+		logger.Info("upload started", "bucket", e.Bucket, "key", e.Key,
+			"upload_id", e.UploadID, "size", e.Size, "resumed", e.Resumed)
+	case PartStarted:
+		logger.Debug("part started", "part", e.Part, "size", e.Size)
+	case PartRetry:
+		logger.Warn("retrying part", append([]any{"operation", e.Operation, "part", e.Part,
+			"attempt", e.Attempt, "backoff", e.Backoff}, errorAttrs(e.Err)...)...)
+	case PartHedged:
+		logger.Info("hedging slow part", "part", e.Part, "after", e.After)
+	case PartCompleted:
+		logger.Debug("part completed", "part", e.Part, "size", e.Size,
+			"etag", e.ETag, "duration", e.Duration)
+	case PartFailed:
+		logger.Warn("part failed", append([]any{"part", e.Part, "duration", e.Duration},
+			errorAttrs(e.Err)...)...)
+	case UploadCompleted:
+		logger.Info("upload completed", "bucket", e.Bucket, "key", e.Key, "upload_id", e.UploadID,
+			"etag", e.ETag, "size", e.Size, "parts", e.Parts, "duration", e.Duration)
+	case UploadAborted:
+		attrs := append([]any{"bucket", e.Bucket, "key", e.Key, "upload_id", e.UploadID},
+			errorAttrs(e.Err)...)
+		switch {
+		case e.Resumable:
+			logger.Warn("upload failed, kept for resuming", attrs...)
+		case e.UploadID == "":
+			logger.Warn("upload failed", attrs...)
+		case e.AbortErr != nil:
+			// The parts stay (and are billed) until the upload is cleaned up
+			attrs = append(attrs, slog.Group("abort", errorAttrs(e.AbortErr)...))
+			logger.Error("upload failed and could not be aborted, incomplete upload remains", attrs...)
+		default:
+			logger.Warn("upload failed and was aborted", attrs...)
+		}
+	case DownloadStarted:
+		logger.Debug("download started", "bucket", e.Bucket, "key", e.Key,
+			"size", e.Size, "etag", e.ETag)
+	case DownloadCompleted:
+		logger.Info("download completed", "bucket", e.Bucket, "key", e.Key,
+			"size", e.Size, "duration", e.Duration)
+	case DownloadFailed:
+		logger.Warn("download failed", append([]any{"bucket", e.Bucket, "key", e.Key},
+			errorAttrs(e.Err)...)...)
+	}
+}
+
+// Secrets in HTTP dumps: credential headers and presigned URL parameters.
+var (
+	secretHeaders = regexp.MustCompile(`(?im)^(authorization|proxy-authorization|cookie|set-cookie|` +
+		`x-amz-security-token|x-amz-server-side-encryption-customer-key|` +
+		`x-amz-copy-source-server-side-encryption-customer-key):[^\r\n]*`)
+	secretParams = regexp.MustCompile(`(?i)(x-amz-(?:signature|credential|security-token)=)[^&\s]+`)
+)
+
+// redactHTTP replaces secrets in an HTTP request or response dump.
+func redactHTTP(dump string) string {
+	dump = secretHeaders.ReplaceAllString(dump, "$1: REDACTED")
+	return secretParams.ReplaceAllString(dump, "${1}REDACTED")
+}
+
+// sdkLogger passes AWS SDK log messages to a slog.Logger at debug level,
+// with secrets redacted.
+type sdkLogger struct {
+	logger *slog.Logger
+}
+
+// Logf implements logging.Logger.
+func (l sdkLogger) Logf(classification logging.Classification, format string, v ...any) {
+	msg := redactHTTP(fmt.Sprintf(format, v...))
+	if classification == logging.Warn {
+		l.logger.Warn(msg, "source", "aws-sdk")
+		return
+	}
+	l.logger.Debug(msg, "source", "aws-sdk")
+}
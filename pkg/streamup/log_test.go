@@ -0,0 +1,124 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestRedactHTTP(t *testing.T) {
+	dump := "PUT /b/k?partNumber=1&X-Amz-Signature=abc123&X-Amz-Credential=AKID%2F2025 HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Authorization: AWS4-HMAC-SHA256 Credential=AKID/20250101/auto/s3/aws4_request, Signature=deadbeef\r\n" +
+		"X-Amz-Security-Token: session-token\r\n" +
+		"X-Amz-Server-Side-Encryption-Customer-Key: c2VjcmV0\r\n" +
+		"X-Amz-Server-Side-Encryption-Customer-Key-Md5: bWQ1\r\n" +
+		"Content-Length: 5\r\n"
+
+	got := redactHTTP(dump)
+	for _, secret := range []string{"abc123", "AKID", "deadbeef", "session-token", "c2VjcmV0"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("redacted dump still contains %q:\n%s", secret, got)
+		}
+	}
+	for _, kept := range []string{"partNumber=1", "Host: localhost", "Customer-Key-Md5: bWQ1", "Content-Length: 5"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("redacted dump lost %q:\n%s", kept, got)
+		}
+	}
+}
+
+func TestSDKLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := sdkLogger{logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+
+	logger.Logf(logging.Debug, "Request\n%s", "Authorization: secret")
+	if strings.Contains(buf.String(), "secret") || !strings.Contains(buf.String(), "level=DEBUG") {
+		t.Errorf("log = %s", buf.String())
+	}
+}
+
+func TestErrorAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	logger.Info("failed", errorAttrs(streamuptest.ErrSlowDown())...)
+	for _, want := range []string{"error_code=SlowDown", "status=503", "request_id=FAKE-REQUEST-ID"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q is missing %s", buf.String(), want)
+		}
+	}
+
+	buf.Reset()
+	logger.Info("failed", errorAttrs(errors.New("boom"))...)
+	if strings.Contains(buf.String(), "error_code") {
+		t.Errorf("plain errors should only log the error: %s", buf.String())
+	}
+}
+
+func TestUpload_Logging(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func(f *streamuptest.Fake)
+		want   []string
+	}{
+		{
+			name:   "retry",
+			inject: func(f *streamuptest.Fake) { f.ThrottlePart(1, 1) },
+			want:   []string{"upload started", "retrying part", "operation=UploadPart", "error_code=SlowDown", "request_id=FAKE-REQUEST-ID", "upload completed"},
+		},
+		{
+			name:   "request retry",
+			inject: func(f *streamuptest.Fake) { f.FailComplete(1) },
+			want:   []string{"retrying request", "operation=CompleteMultipartUpload", "error_code=InternalError", "upload completed"},
+		},
+		{
+			name:   "aborted",
+			inject: func(f *streamuptest.Fake) { f.FailComplete(0) },
+			want:   []string{"upload started", "upload failed and was aborted", "error_code=InternalError"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			tt.inject(fake)
+			data := testData(6 * 1024 * 1024)
+
+			var buf bytes.Buffer
+			uploader := newFakeUploader(t, fake, Config{
+				FileSize: int64(len(data)),
+				Logger:   slog.New(slog.NewTextHandler(&buf, nil)),
+			})
+			_ = uploader.Upload(bytes.NewReader(data))
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("log is missing %q:\n%s", want, buf.String())
+				}
+			}
+			if strings.Contains(buf.String(), "part completed") {
+				t.Error("part progress should only be logged at debug level")
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// rateLimitChunk is the largest read that is let through in one go, so that
+// throughput stays smooth instead of arriving in part-sized bursts.
+const rateLimitChunk = 32 * 1024
+
+// RateLimiter is a token bucket that limits throughput in bytes per second.
+//
+// A single RateLimiter is safe for concurrent use and can be shared between
+// upload workers, several Uploaders and Downloaders so that together they
+// stay under one bandwidth budget.
+type RateLimiter struct {
+	rate  float64 // Tokens (bytes) added per second
+	burst float64 // Maximum tokens that can accumulate while idle
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing bytesPerSecond bytes per second.
+// The bucket holds a tenth of a second's worth of tokens, so an idle limiter
+// never allows a large burst.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	burst := float64(bytesPerSecond) / 10
+	if burst < rateLimitChunk {
+		burst = rateLimitChunk
+	}
+	return &RateLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// WaitN blocks until n bytes may be transferred or ctx is done.
+//
+// Tokens are reserved up front, so concurrent callers queue behind each
+// other in order rather than racing for the same refill.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / l.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader returns a reader that draws from the limiter as data is read.
+func (l *RateLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &rateLimitedReader{ctx: ctx, reader: r, limiter: l}
+}
+
+// rateLimitedReader throttles reads through a shared RateLimiter.
+type rateLimitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *RateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > rateLimitChunk {
+		p = p[:rateLimitChunk]
+	}
+
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// rateLimitedHTTPClient throttles request bodies as the transport sends them.
+//
+// Throttling at this level (rather than wrapping the part body) keeps the SDK's
+// payload signing and checksum passes, which read the body before sending,
+// from consuming the bandwidth budget.
+type rateLimitedHTTPClient struct {
+	client  s3.HTTPClient
+	limiter *RateLimiter
+}
+
+func (c *rateLimitedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{c.limiter.Reader(req.Context(), req.Body), req.Body}
+	}
+	return c.client.Do(req)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Reader(t *testing.T) {
+	// 1 MB/s with a 100 KB burst: reading 300 KB takes at least ~200ms
+	limiter := NewRateLimiter(1024 * 1024)
+	data := bytes.Repeat([]byte("x"), 300*1024)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, limiter.Reader(context.Background(), bytes.NewReader(data)))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("io.Copy() unexpected error = %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("copied %d bytes, want %d", n, len(data))
+	}
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("read took %v, want at least 150ms", elapsed)
+	}
+}
+
+func TestRateLimiter_Shared(t *testing.T) {
+	// Two readers sharing a limiter split the rate rather than doubling it
+	limiter := NewRateLimiter(1024 * 1024)
+	data := bytes.Repeat([]byte("x"), 150*1024)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = io.Copy(io.Discard, limiter.Reader(context.Background(), bytes.NewReader(data)))
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("shared reads took %v, want at least 150ms", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Drain the initial burst, then the next wait must observe the cancellation
+	_ = limiter.WaitN(ctx, rateLimitChunk)
+	if err := limiter.WaitN(ctx, rateLimitChunk); err != context.Canceled {
+		t.Errorf("WaitN() error = %v, want context.Canceled", err)
+	}
+}
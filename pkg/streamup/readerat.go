@@ -0,0 +1,210 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// sectionSource is a seekable source whose parts the workers read directly,
+// so no single goroutine has to read and copy every byte. A failed part is
+// retried by reading its section again instead of keeping it in memory.
+type sectionSource struct {
+	reader io.ReaderAt
+	base   int64 // Offset of the first byte to upload
+	size   int64
+}
+
+// newSectionSource returns a sectionSource if reader can be read in parallel
+// (it implements io.ReaderAt and io.Seeker) and holds exactly FileSize bytes
+// from its current position. Otherwise it returns nil and the upload streams
+// through the producer. Compressed and encrypted uploads always stream.
+func (u *Uploader) newSectionSource(reader io.Reader) (*sectionSource, error) {
+	if u.compression != "" || u.envelope != nil || u.config.FileSize == UnknownSize {
+		return nil, nil
+	}
+	readerAt, ok := reader.(io.ReaderAt)
+	if !ok {
+		return nil, nil
+	}
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return nil, nil
+	}
+
+	// Pipes and terminals (e.g. os.Stdin) implement the interfaces but can't seek
+	base, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, nil
+	}
+	if _, err := seeker.Seek(base, io.SeekStart); err != nil {
+		return nil, &UploadError{Operation: "reading data", Err: err}
+	}
+
+	if end-base != u.config.FileSize {
+		return nil, nil
+	}
+	return &sectionSource{reader: readerAt, base: base, size: end - base}, nil
+}
+
+// producesections sends a section per part to the workers.
+func (u *Uploader) producesections(partsChan chan<- part) error {
+	var offset int64
+	for partNumber := int32(1); offset < u.source.size; partNumber++ {
+		// Check for cancellation
+		select {
+		case <-u.ctx.Done():
+			return u.ctx.Err()
+		default:
+		}
+
+		size := min(u.schedule.PartSize(partNumber), u.source.size-offset)
+		if int(partNumber) > u.config.ServiceLimits.MaxParts {
+			return &UploadError{
+				Operation: "reading data",
+				Err:       fmt.Errorf("stream exceeds the maximum of %d parts", u.config.ServiceLimits.MaxParts),
+			}
+		}
+
+		// Already uploaded by a previous attempt: just check it still lines up
+		if prev, done := u.resumed[partNumber]; done {
+			if prev.Size != size {
+				return &UploadError{
+					Operation: "resuming upload",
+					Err: fmt.Errorf("part %d is %d bytes but was %d bytes in the previous attempt (has the source changed?)",
+						partNumber, size, prev.Size),
+				}
+			}
+		} else {
+			section := io.NewSectionReader(u.source.reader, u.source.base+offset, size)
+			select {
+			case partsChan <- part{number: partNumber, section: section}:
+			case <-u.ctx.Done():
+				return u.ctx.Err()
+			}
+		}
+
+		offset += size
+	}
+
+	return nil
+}
+
+// hashSource feeds the whole source to the object checksums. The workers read
+// parts out of order, so this is a separate sequential pass over the source.
+func (u *Uploader) hashSource(ctx context.Context) error {
+	var writers []io.Writer
+	if u.checksumHash != nil {
+		writers = append(writers, u.checksumHash)
+	}
+	if u.objectChecksumHash != nil {
+		writers = append(writers, u.objectChecksumHash)
+	}
+
+	section := io.NewSectionReader(u.source.reader, u.source.base, u.source.size)
+	if _, err := io.Copy(io.MultiWriter(writers...), &contextReader{ctx: ctx, reader: section}); err != nil {
+		return &UploadError{Operation: "reading data", Err: err}
+	}
+	return nil
+}
+
+// body returns a reader over the part's data, starting from the beginning.
+func (p part) body() io.ReadSeeker {
+	if p.section != nil {
+		return io.NewSectionReader(p.section, 0, p.section.Size())
+	}
+	return bytes.NewReader(p.data)
+}
+
+// size returns the length of the part.
+func (p part) size() int64 {
+	if p.section != nil {
+		return p.section.Size()
+	}
+	return int64(len(p.data))
+}
+
+// partSums holds the per-part checksums an upload needs.
+type partSums struct {
+	checksum   string  // Base64 server checksum, if enabled
+	contentMD5 *string // Content-MD5 header, if enabled
+	digest     []byte  // MD5 of the part, if S3 uses it as the part's ETag
+}
+
+// partSums computes the part's checksums in a single pass over its data.
+func (u *Uploader) partSums(p part) (partSums, error) {
+	var sums partSums
+	var writers []io.Writer
+
+	server := newServerChecksumHash(u.config.ServerChecksumAlgorithm)
+	if server != nil {
+		writers = append(writers, server)
+	}
+	var digest hash.Hash
+	if u.config.SendContentMD5 || u.sse.etagIsMD5() {
+		digest = md5.New()
+		writers = append(writers, digest)
+	}
+	if len(writers) == 0 {
+		return sums, nil
+	}
+
+	n, err := io.Copy(io.MultiWriter(writers...), p.body())
+	if err != nil {
+		return sums, err
+	}
+	if n != p.size() {
+		return sums, fmt.Errorf("read %d bytes of part %d, expected %d (has the source changed?)", n, p.number, p.size())
+	}
+
+	if server != nil {
+		sums.checksum = base64.StdEncoding.EncodeToString(server.Sum(nil))
+	}
+	if digest != nil {
+		sum := digest.Sum(nil)
+		if u.config.SendContentMD5 {
+			sums.contentMD5 = aws.String(base64.StdEncoding.EncodeToString(sum))
+		}
+		if u.sse.etagIsMD5() {
+			sums.digest = sum
+		}
+	}
+	return sums, nil
+}
+
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestNewSectionSource(t *testing.T) {
+	data := testData(1024)
+
+	tests := []struct {
+		name   string
+		config Config
+		reader io.Reader
+		want   bool
+	}{
+		{"seekable", Config{FileSize: 1024}, bytes.NewReader(data), true},
+		{"not seekable", Config{FileSize: 1024}, bytes.NewBuffer(data), false},
+		{"size mismatch", Config{FileSize: 1000}, bytes.NewReader(data), false},
+		{"unknown size", Config{FileSize: UnknownSize}, bytes.NewReader(data), false},
+		{"compressed", Config{FileSize: 1024, Compression: CompressionGzip}, bytes.NewReader(data), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploader := newFakeUploader(t, streamuptest.NewFake(), tt.config)
+			source, err := uploader.newSectionSource(tt.reader)
+			if err != nil {
+				t.Fatalf("newSectionSource() error = %v", err)
+			}
+			if (source != nil) != tt.want {
+				t.Errorf("newSectionSource() = %v, want section source %v", source, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpload_Sections(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.MinPartSize = defaultMinPartSize
+	fake.ThrottlePart(2, 2)
+
+	// Only the bytes after the reader's current position are uploaded
+	data := testData(12*1024*1024 + 100)
+	reader := bytes.NewReader(data)
+	if _, err := reader.Seek(100, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	want := data[100:]
+
+	uploader := newFakeUploader(t, fake, Config{
+		FileSize:                int64(len(want)),
+		CalculateChecksum:       true,
+		ChecksumAlgorithm:       "sha256",
+		ServerChecksumAlgorithm: ServerChecksumCRC32,
+		ServerChecksumType:      ServerChecksumFullObject,
+		SendContentMD5:          true,
+		VerifyUpload:            true,
+	})
+	if err := uploader.Upload(reader); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if uploader.source == nil {
+		t.Error("a seekable reader should be uploaded in sections")
+	}
+	obj, ok := fake.Object("test-bucket", "test-key")
+	if !ok || !bytes.Equal(obj.Data, want) {
+		t.Fatal("stored data does not match the source")
+	}
+	if got := fake.Calls(streamuptest.OpUploadPart); got != 5 {
+		t.Errorf("UploadPart calls = %d, want 5 (retries read the section again)", got)
+	}
+
+	sum := sha256.Sum256(want)
+	if got := uploader.GetChecksum(); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("GetChecksum() = %q, want %x", got, sum)
+	}
+	if uploader.GetServerChecksum() != obj.Checksum {
+		t.Errorf("GetServerChecksum() = %q, want %q", uploader.GetServerChecksum(), obj.Checksum)
+	}
+	if v := uploader.GetVerification(); v == nil || !v.ETagMatches() || !v.SizeMatches() {
+		t.Errorf("GetVerification() = %+v, want matching size and ETag", v)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// RetryPolicy decides whether a failed request is retried and how long to
+// wait first. An upload calls it from all of its workers at once.
+type RetryPolicy interface {
+	// Retry is called after a request fails for the attempt'th time
+	// (starting at 1). It returns how long to wait before trying again, or
+	// an error to give up with (usually err itself).
+	Retry(attempt int, err error) (time.Duration, error)
+}
+
+// DefaultRetryPolicy is the RetryPolicy used when Config.RetryPolicy is nil,
+// built from Config.MaxRetries, RetryDelay, MaxRetryDelay, RetryMultiplier
+// and RetryBudget.
+//
+// Errors are classified by S3 error code and HTTP status (see
+// isRetryableError). The wait is a random duration of up to the exponential
+// backoff for the attempt ("full jitter"), so workers that fail together do
+// not retry together. A Retry-After header from the service sets the minimum
+// wait, up to MaxDelay.
+//
+// Retries count against Budget for as long as the policy is used, so use a
+// new policy for each upload.
+type DefaultRetryPolicy struct {
+	MaxRetries int           // Retries per request
+	BaseDelay  time.Duration // Backoff before the first retry
+	MaxDelay   time.Duration // Longest wait between attempts
+	Multiplier float64       // Backoff growth per attempt
+	Budget     int           // Total retries across all requests (0 = unlimited)
+
+	retries atomic.Int64
+}
+
+// Retry implements RetryPolicy.
+func (p *DefaultRetryPolicy) Retry(attempt int, err error) (time.Duration, error) {
+	if attempt > p.MaxRetries || !isRetryableError(err) {
+		return 0, err
+	}
+	if p.Budget > 0 && p.retries.Add(1) > int64(p.Budget) {
+		return 0, &RetryBudgetError{Budget: p.Budget, Err: err}
+	}
+
+	backoff := p.backoff(attempt - 1)
+	delay := time.Duration(rand.Int64N(int64(backoff) + 1))
+	if after, ok := retryAfter(err); ok && after > delay {
+		delay = min(after, p.MaxDelay)
+	}
+	return delay, nil
+}
+
+// backoff returns the exponential backoff for a 0-based retry attempt:
+// BaseDelay * Multiplier^attempt, capped at MaxDelay.
+func (p *DefaultRetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(attempt))
+	if backoff > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(backoff)
+}
+
+// retryAfter returns the wait requested by a Retry-After header on a service
+// error, given in seconds or as an HTTP date.
+func retryAfter(err error) (time.Duration, bool) {
+	var respErr *smithyhttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.Response == nil {
+		return 0, false
+	}
+
+	value := respErr.Response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// withTimeout calls fn with a context that expires after timeout (0 = never),
+// reporting an expired attempt as a TimeoutError.
+func withTimeout(ctx context.Context, operation string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout == 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Operation: operation, Timeout: timeout}
+	}
+	return err
+}
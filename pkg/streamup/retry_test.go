@@ -15,13 +15,18 @@
 package streamup
 
 import (
+	"bytes"
 	"errors"
 	"net"
+	"net/http"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
 )
 
 // Mock API error type for testing
@@ -82,6 +87,11 @@ func TestIsRetryableError(t *testing.T) {
 			err:       &mockAPIError{code: "500InternalServerError"},
 			retryable: true,
 		},
+		{
+			name:      "API PreconditionFailed",
+			err:       &mockAPIError{code: "PreconditionFailed"},
+			retryable: false,
+		},
 		{
 			name:      "Generic error (conservative approach)",
 			err:       errors.New("unknown error"),
@@ -99,7 +109,7 @@ func TestIsRetryableError(t *testing.T) {
 	}
 }
 
-func TestCalculateBackoff(t *testing.T) {
+func TestDefaultRetryPolicyBackoff(t *testing.T) {
 	tests := []struct {
 		name            string
 		retryDelay      int
@@ -192,10 +202,10 @@ func TestCalculateBackoff(t *testing.T) {
 				t.Fatalf("Failed to create uploader: %v", err)
 			}
 
-			backoff := uploader.calculateBackoff(tt.attempt)
+			backoff := uploader.defaultRetryPolicy().backoff(tt.attempt)
 
 			if backoff < tt.wantMin || backoff > tt.wantMax {
-				t.Errorf("calculateBackoff() = %v, want between %v and %v", backoff, tt.wantMin, tt.wantMax)
+				t.Errorf("backoff() = %v, want between %v and %v", backoff, tt.wantMin, tt.wantMax)
 			}
 		})
 	}
@@ -292,9 +302,141 @@ func TestBackoffProgression(t *testing.T) {
 	}
 
 	for attempt, expected := range expectedBackoffs {
-		backoff := uploader.calculateBackoff(attempt)
+		backoff := uploader.defaultRetryPolicy().backoff(attempt)
 		if backoff != expected {
-			t.Errorf("Attempt %d: calculateBackoff() = %v, want %v", attempt, backoff, expected)
+			t.Errorf("Attempt %d: backoff() = %v, want %v", attempt, backoff, expected)
+		}
+	}
+}
+
+func TestIsRetryableError_HTTPStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"502 Bad Gateway", streamuptest.APIError(http.StatusBadGateway, "BadGateway", ""), true},
+		{"429 Too Many Requests", streamuptest.APIError(http.StatusTooManyRequests, "TooManyRequests", ""), true},
+		{"400 RequestTimeout", streamuptest.APIError(http.StatusBadRequest, "RequestTimeout", ""), true},
+		{"404 NoSuchUpload", streamuptest.APIError(http.StatusNotFound, "NoSuchUpload", ""), false},
+		{"403 AccessDenied", streamuptest.APIError(http.StatusForbidden, "AccessDenied", ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.retryable {
+				t.Errorf("isRetryableError() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+// withRetryAfter adds a Retry-After header to a fake service error.
+func withRetryAfter(err error, value string) error {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		respErr.Response.Header.Set("Retry-After", value)
+	}
+	return err
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := &DefaultRetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+		Multiplier: 2,
+	}
+	slowDown := streamuptest.ErrSlowDown()
+
+	// Full jitter: anywhere from zero up to the exponential backoff
+	for attempt := 1; attempt <= 3; attempt++ {
+		delay, err := policy.Retry(attempt, slowDown)
+		if err != nil {
+			t.Fatalf("Retry(%d) error = %v", attempt, err)
 		}
+		if limit := policy.backoff(attempt - 1); delay < 0 || delay > limit {
+			t.Errorf("Retry(%d) delay = %v, want at most %v", attempt, delay, limit)
+		}
+	}
+
+	if _, err := policy.Retry(4, slowDown); err != slowDown {
+		t.Errorf("Retry() after MaxRetries error = %v, want the original error", err)
+	}
+	notFound := streamuptest.APIError(http.StatusNotFound, "NoSuchUpload", "")
+	if _, err := policy.Retry(1, notFound); err != notFound {
+		t.Errorf("Retry() for a client error = %v, want the original error", err)
+	}
+
+	// Retry-After sets the minimum wait, up to MaxDelay
+	if delay, _ := policy.Retry(1, withRetryAfter(streamuptest.ErrSlowDown(), "1")); delay != time.Second {
+		t.Errorf("Retry-After: 1 delay = %v, want 1s", delay)
+	}
+	if delay, _ := policy.Retry(1, withRetryAfter(streamuptest.ErrSlowDown(), "120")); delay != time.Second {
+		t.Errorf("Retry-After: 120 delay = %v, want MaxDelay", delay)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if delay, _ := policy.Retry(1, withRetryAfter(streamuptest.ErrSlowDown(), date)); delay != time.Second {
+		t.Errorf("Retry-After: %s delay = %v, want MaxDelay", date, delay)
+	}
+}
+
+func TestDefaultRetryPolicy_Budget(t *testing.T) {
+	policy := &DefaultRetryPolicy{MaxRetries: 3, MaxDelay: time.Millisecond, Multiplier: 2, Budget: 2}
+	slowDown := streamuptest.ErrSlowDown()
+
+	for i := 0; i < 2; i++ {
+		if _, err := policy.Retry(1, slowDown); err != nil {
+			t.Fatalf("Retry() within budget error = %v", err)
+		}
+	}
+
+	_, err := policy.Retry(1, slowDown)
+	var budgetErr *RetryBudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Budget != 2 || !errors.Is(err, slowDown) {
+		t.Errorf("Retry() over budget error = %v, want RetryBudgetError wrapping the failure", err)
+	}
+}
+
+// retryPolicyFunc adapts a function to RetryPolicy.
+type retryPolicyFunc func(attempt int, err error) (time.Duration, error)
+
+func (f retryPolicyFunc) Retry(attempt int, err error) (time.Duration, error) { return f(attempt, err) }
+
+func TestUpload_RetryPolicy(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.ThrottlePart(1, 2)
+	data := testData(6 * 1024 * 1024)
+
+	var attempts []int
+	var mu sync.Mutex
+	uploader := newFakeUploader(t, fake, Config{
+		FileSize: int64(len(data)),
+		RetryPolicy: retryPolicyFunc(func(attempt int, err error) (time.Duration, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts = append(attempts, attempt)
+			return 0, nil
+		}),
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("policy saw attempts %v, want [1 2]", attempts)
+	}
+
+	// A policy that gives up fails the upload with its error
+	fake = streamuptest.NewFake()
+	fake.ThrottlePart(1, 1)
+	errGiveUp := errors.New("give up")
+	uploader = newFakeUploader(t, fake, Config{
+		FileSize: int64(len(data)),
+		RetryPolicy: retryPolicyFunc(func(int, error) (time.Duration, error) {
+			return 0, errGiveUp
+		}),
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); !errors.Is(err, errGiveUp) {
+		t.Errorf("Upload() error = %v, want the policy's error", err)
 	}
 }
@@ -0,0 +1,84 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import "io"
+
+// sourceSize converts a number of bytes produced for upload into the number
+// of bytes read from the source: plaintext when encrypting, and the bytes
+// consumed by the compressor when compressing.
+func (u *Uploader) sourceSize(produced int64) int64 {
+	if u.compression != "" {
+		return u.sourceBytes.Load()
+	}
+	if u.config.Encryption != nil {
+		return plaintextSize(produced, u.config.Encryption.ChunkSize)
+	}
+	return produced
+}
+
+// checkSourceSize compares the size of the source with the declared FileSize
+// before the upload is completed. A mismatch fails the upload with
+// Config.StrictSize and is logged otherwise.
+func (u *Uploader) checkSourceSize(actual int64) error {
+	if u.originalSize == UnknownSize || actual == u.originalSize {
+		return nil
+	}
+
+	mismatch := &SizeMismatchError{Expected: u.originalSize, Actual: actual}
+	if u.config.StrictSize {
+		return &UploadError{Operation: "reading data", Err: mismatch}
+	}
+	u.logger.Warn("source size differs from FileSize", "expected", mismatch.Expected, "actual", mismatch.Actual)
+	return nil
+}
+
+// checkSeekableSize fails with a SizeMismatchError if a seekable source
+// doesn't hold FileSize bytes from its current position.
+func (u *Uploader) checkSeekableSize(seeker io.Seeker) error {
+	base, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return &UploadError{Operation: "reading data", Err: err}
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return &UploadError{Operation: "reading data", Err: err}
+	}
+	if _, err := seeker.Seek(base, io.SeekStart); err != nil {
+		return &UploadError{Operation: "reading data", Err: err}
+	}
+
+	if end-base != u.originalSize {
+		return &UploadError{Operation: "reading data", Err: &SizeMismatchError{Expected: u.originalSize, Actual: end - base}}
+	}
+	return nil
+}
+
+// sizeLimitReader fails as soon as a source turns out to be longer than
+// expected, rather than reading on until the part limit.
+type sizeLimitReader struct {
+	reader   io.Reader
+	expected int64
+	read     int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.expected {
+		return n, &SizeMismatchError{Expected: r.expected, Actual: r.read}
+	}
+	return n, err
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestUpload_StrictSize(t *testing.T) {
+	const mib = 1024 * 1024
+	tests := []struct {
+		name     string
+		declared int64
+		data     []byte
+		stream   bool // Hide io.Seeker, like an HTTP body
+		actual   int64
+	}{
+		{"truncated stream", 12 * mib, testData(7 * mib), true, 7 * mib},
+		{"truncated file", 12 * mib, testData(7 * mib), false, 7 * mib},
+		{"truncated small object", 4 * mib, testData(mib), true, mib},
+		{"overlong stream stops early", 6 * mib, testData(40 * mib), true, 6*mib + 1},
+		{"overlong file", 6 * mib, testData(7 * mib), false, 7 * mib},
+		{"file twice as long", 6 * mib, testData(12 * mib), false, 12 * mib},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			uploader := newFakeUploader(t, fake, Config{FileSize: tt.declared, StrictSize: true})
+
+			var reader io.Reader = bytes.NewReader(tt.data)
+			if tt.stream {
+				reader = struct{ io.Reader }{reader}
+			}
+			err := uploader.Upload(reader)
+
+			var mismatch *SizeMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("Upload() error = %v, want SizeMismatchError", err)
+			}
+			if mismatch.Expected != tt.declared || mismatch.Actual < tt.actual || mismatch.Actual > int64(len(tt.data)) {
+				t.Errorf("mismatch = %d of %d, want %d", mismatch.Actual, mismatch.Expected, tt.actual)
+			}
+			if _, ok := fake.Object("test-bucket", "test-key"); ok {
+				t.Error("object should not be stored")
+			}
+			if ids := fake.Uploads(); len(ids) != 0 {
+				t.Errorf("uploads %v were not aborted", ids)
+			}
+			if !tt.stream && fake.Calls(streamuptest.OpCreateMultipartUpload) != 0 {
+				t.Error("a seekable source of the wrong size should fail before the upload starts")
+			}
+		})
+	}
+}
+
+func TestUpload_StrictSizeMatches(t *testing.T) {
+	data := testData(6 * 1024 * 1024)
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"plain", Config{}},
+		{"encrypted", Config{Encryption: &EncryptionConfig{Key: bytes.Repeat([]byte{1}, 32)}}},
+		{"compressed", Config{Compression: CompressionGzip}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			cfg := tt.config
+			cfg.FileSize = int64(len(data))
+			cfg.StrictSize = true
+
+			uploader := newFakeUploader(t, fake, cfg)
+			if err := uploader.Upload(struct{ io.Reader }{bytes.NewReader(data)}); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUpload_SizeMismatchWarns(t *testing.T) {
+	fake := streamuptest.NewFake()
+	data := testData(7 * 1024 * 1024)
+
+	var buf bytes.Buffer
+	uploader := newFakeUploader(t, fake, Config{
+		FileSize: 12 * 1024 * 1024,
+		Logger:   slog.New(slog.NewTextHandler(&buf, nil)),
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if obj, ok := fake.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+		t.Error("without StrictSize the object should still be stored")
+	}
+	if !strings.Contains(buf.String(), "source size differs from FileSize") {
+		t.Errorf("log is missing the size warning:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Server-side encryption modes.
+const (
+	SSES3      = "AES256"       // SSE-S3: keys managed by the service
+	SSEKMS     = "aws:kms"      // SSE-KMS: keys managed by AWS KMS
+	SSEKMSDSSE = "aws:kms:dsse" // DSSE-KMS: dual-layer encryption with AWS KMS
+)
+
+// sseCustomerAlgorithm is the only algorithm S3 supports for SSE-C.
+const sseCustomerAlgorithm = "AES256"
+
+// LoadSSECustomerKey reads a 32-byte SSE-C key from a file. The file may
+// contain the raw key, or the key encoded as hex or base64.
+func LoadSSECustomerKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSE-C key: %w", err)
+	}
+	if len(data) == 32 {
+		return data, nil
+	}
+	return parseEncryptionKey(string(data))
+}
+
+// validateSSE checks a server-side encryption configuration.
+func validateSSE(sse, kmsKeyID string, kmsContext map[string]string, customerKey []byte) error {
+	switch sse {
+	case "", SSES3, SSEKMS, SSEKMSDSSE:
+	default:
+		return &ValidationError{
+			Field:   "ServerSideEncryption",
+			Message: fmt.Sprintf("must be '%s', '%s' or '%s'", SSES3, SSEKMS, SSEKMSDSSE),
+		}
+	}
+
+	isKMS := sse == SSEKMS || sse == SSEKMSDSSE
+	if kmsKeyID != "" && !isKMS {
+		return &ValidationError{Field: "SSEKMSKeyID", Message: "requires ServerSideEncryption 'aws:kms' or 'aws:kms:dsse'"}
+	}
+	if len(kmsContext) > 0 && !isKMS {
+		return &ValidationError{Field: "SSEKMSEncryptionContext", Message: "requires ServerSideEncryption 'aws:kms' or 'aws:kms:dsse'"}
+	}
+
+	if customerKey != nil {
+		if len(customerKey) != 32 {
+			return &ValidationError{
+				Field:   "SSECustomerKey",
+				Message: fmt.Sprintf("must be 32 bytes, got %d", len(customerKey)),
+			}
+		}
+		if sse != "" {
+			return &ValidationError{Field: "SSECustomerKey", Message: "cannot be combined with ServerSideEncryption"}
+		}
+	}
+
+	return nil
+}
+
+// sseHeaders holds the request fields for server-side encryption.
+type sseHeaders struct {
+	sse        types.ServerSideEncryption
+	kmsKeyID   *string
+	kmsContext *string
+
+	// SSE-C: sent on every request that reads or writes object data
+	customerAlgorithm *string
+	customerKey       *string
+	customerKeyMD5    *string
+}
+
+// newSSEHeaders encodes a validated server-side encryption configuration.
+func newSSEHeaders(sse, kmsKeyID string, kmsContext map[string]string, customerKey []byte) (sseHeaders, error) {
+	h := sseHeaders{
+		sse:      types.ServerSideEncryption(sse),
+		kmsKeyID: optionalString(kmsKeyID),
+	}
+
+	// The encryption context is sent as base64-encoded JSON
+	if len(kmsContext) > 0 {
+		data, err := json.Marshal(kmsContext)
+		if err != nil {
+			return h, err
+		}
+		h.kmsContext = aws.String(base64.StdEncoding.EncodeToString(data))
+	}
+
+	if customerKey != nil {
+		sum := md5.Sum(customerKey)
+		h.customerAlgorithm = aws.String(sseCustomerAlgorithm)
+		h.customerKey = aws.String(base64.StdEncoding.EncodeToString(customerKey))
+		h.customerKeyMD5 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+	}
+
+	return h, nil
+}
+
+// etagIsMD5 reports whether S3 computes ETags as MD5 digests under this
+// encryption mode. SSE-KMS and SSE-C objects get opaque ETags.
+func (h sseHeaders) etagIsMD5() bool {
+	return h.sse != types.ServerSideEncryptionAwsKms &&
+		h.sse != types.ServerSideEncryptionAwsKmsDsse &&
+		h.customerKey == nil
+}
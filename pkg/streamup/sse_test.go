@@ -0,0 +1,115 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestValidateSSE(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+
+	tests := []struct {
+		name        string
+		sse         string
+		kmsKeyID    string
+		kmsContext  map[string]string
+		customerKey []byte
+		errContains string
+	}{
+		{name: "none"},
+		{name: "SSE-S3", sse: SSES3},
+		{name: "SSE-KMS with key", sse: SSEKMS, kmsKeyID: "alias/backups", kmsContext: map[string]string{"app": "db"}},
+		{name: "SSE-C", customerKey: key},
+		{name: "unknown mode", sse: "rot13", errContains: "ServerSideEncryption"},
+		{name: "KMS key without KMS", sse: SSES3, kmsKeyID: "alias/backups", errContains: "SSEKMSKeyID"},
+		{name: "KMS context without KMS", kmsContext: map[string]string{"app": "db"}, errContains: "SSEKMSEncryptionContext"},
+		{name: "short SSE-C key", customerKey: key[:16], errContains: "32 bytes"},
+		{name: "SSE-C with SSE-S3", sse: SSES3, customerKey: key, errContains: "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSSE(tt.sse, tt.kmsKeyID, tt.kmsContext, tt.customerKey)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("validateSSE() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errContains) {
+				t.Errorf("validateSSE() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestNewSSEHeaders(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	h, err := newSSEHeaders("", "", nil, key)
+	if err != nil {
+		t.Fatalf("newSSEHeaders() unexpected error = %v", err)
+	}
+
+	sum := md5.Sum(key)
+	if *h.customerAlgorithm != "AES256" {
+		t.Errorf("customerAlgorithm = %q, want AES256", *h.customerAlgorithm)
+	}
+	if *h.customerKey != base64.StdEncoding.EncodeToString(key) {
+		t.Errorf("customerKey = %q, want base64 of key", *h.customerKey)
+	}
+	if *h.customerKeyMD5 != base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Errorf("customerKeyMD5 = %q, want base64 MD5 of key", *h.customerKeyMD5)
+	}
+	if h.etagIsMD5() {
+		t.Error("etagIsMD5() = true for SSE-C, want false")
+	}
+
+	h, err = newSSEHeaders(SSEKMS, "", map[string]string{"app": "db"}, nil)
+	if err != nil {
+		t.Fatalf("newSSEHeaders() unexpected error = %v", err)
+	}
+	if want := base64.StdEncoding.EncodeToString([]byte(`{"app":"db"}`)); *h.kmsContext != want {
+		t.Errorf("kmsContext = %q, want %q", *h.kmsContext, want)
+	}
+	if h.etagIsMD5() {
+		t.Error("etagIsMD5() = true for SSE-KMS, want false")
+	}
+
+	h, _ = newSSEHeaders(SSES3, "", nil, nil)
+	if !h.etagIsMD5() {
+		t.Error("etagIsMD5() = false for SSE-S3, want true")
+	}
+}
+
+func TestDownloader_CheckSSE(t *testing.T) {
+	d := &Downloader{config: DownloadConfig{ServerSideEncryption: SSEKMS, SSEKMSKeyID: "key-id"}}
+
+	arn := "arn:aws:kms:us-east-1:123456789012:key/key-id"
+	if err := d.checkSSE(types.ServerSideEncryptionAwsKms, arn); err != nil {
+		t.Errorf("checkSSE() unexpected error = %v", err)
+	}
+	if err := d.checkSSE(types.ServerSideEncryptionAes256, ""); err == nil {
+		t.Error("checkSSE() with SSE-S3 object succeeded, want error")
+	}
+	if err := d.checkSSE(types.ServerSideEncryptionAwsKms, "arn:aws:kms:us-east-1:123456789012:key/other"); err == nil {
+		t.Error("checkSSE() with other KMS key succeeded, want error")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// idleReadSize caps each read from a watched source.
+const idleReadSize = 1024 * 1024
+
+// idleReader fails with ErrSourceStalled when a read from the source returns
+// nothing for longer than timeout. A blocked Read can't be interrupted, so
+// reads happen on another goroutine into a private buffer; after a stall the
+// source is closed (if it is an io.Closer) to release that goroutine.
+type idleReader struct {
+	reader  io.Reader
+	timeout time.Duration
+	buf     []byte
+	results chan idleRead
+	err     error // Sticky once the source stalled
+}
+
+type idleRead struct {
+	n   int
+	err error
+}
+
+// newIdleReader watches reader for stalls longer than timeout.
+func newIdleReader(reader io.Reader, timeout time.Duration) *idleReader {
+	return &idleReader{
+		reader:  reader,
+		timeout: timeout,
+		results: make(chan idleRead, 1),
+	}
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if r.buf == nil {
+		r.buf = make([]byte, idleReadSize)
+	}
+	buf := r.buf[:min(len(p), len(r.buf))]
+	go func() {
+		n, err := r.reader.Read(buf)
+		r.results <- idleRead{n: n, err: err}
+	}()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-r.results:
+		return copy(p, buf[:res.n]), res.err
+	case <-timer.C:
+		// The read is still running and owns r.buf, so never read again
+		r.err = fmt.Errorf("%w: no data for %s", ErrSourceStalled, r.timeout)
+		if closer, ok := r.reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return 0, r.err
+	}
+}
+
+// isSeekable reports whether reader can seek, i.e. is a regular file or held
+// in memory rather than a pipe or network stream.
+func isSeekable(reader io.Reader) bool {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := seeker.Seek(0, io.SeekCurrent)
+	return err == nil
+}
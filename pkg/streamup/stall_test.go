@@ -0,0 +1,89 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestIdleReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	go pw.Write([]byte("hello"))
+
+	reader := newIdleReader(pr, 50*time.Millisecond)
+	buf := make([]byte, 10)
+	if n, err := reader.Read(buf); err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("Read() = %q, %v, want hello", buf[:n], err)
+	}
+
+	// Nothing more arrives
+	if _, err := reader.Read(buf); !errors.Is(err, ErrSourceStalled) {
+		t.Fatalf("Read() error = %v, want ErrSourceStalled", err)
+	}
+	if _, err := reader.Read(buf); !errors.Is(err, ErrSourceStalled) {
+		t.Errorf("Read() after a stall error = %v, want ErrSourceStalled", err)
+	}
+	if _, err := pw.Write([]byte("late")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("stalled source should be closed, Write() error = %v", err)
+	}
+}
+
+func TestIsSeekable(t *testing.T) {
+	pr, _ := io.Pipe()
+	if isSeekable(pr) {
+		t.Error("a pipe is not seekable")
+	}
+	if !isSeekable(bytes.NewReader(nil)) {
+		t.Error("bytes.Reader is seekable")
+	}
+}
+
+func TestUpload_SourceStalled(t *testing.T) {
+	fake := streamuptest.NewFake()
+	pr, pw := io.Pipe()
+	go pw.Write(testData(6 * 1024 * 1024))
+
+	uploader := newFakeUploader(t, fake, Config{
+		FileSize:          UnknownSize,
+		SourceIdleTimeout: 50 * time.Millisecond,
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- uploader.Upload(pr) }()
+
+	select {
+	case err := <-done:
+		var uploadErr *UploadError
+		if !errors.Is(err, ErrSourceStalled) || !errors.As(err, &uploadErr) {
+			t.Errorf("Upload() error = %v, want an UploadError for ErrSourceStalled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Upload() did not give up on a stalled source")
+	}
+
+	// The first part was read, so the upload had started and must be aborted
+	if fake.Calls(streamuptest.OpCreateMultipartUpload) != 1 {
+		t.Error("the multipart upload should have been started")
+	}
+	if ids := fake.Uploads(); len(ids) != 0 {
+		t.Errorf("uploads %v were not aborted", ids)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamuptest
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var crc64NVMETable = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+
+// newHash returns the hash for a checksum algorithm.
+func newHash(algorithm types.ChecksumAlgorithm) hash.Hash {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return crc32.NewIEEE()
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case types.ChecksumAlgorithmCrc64nvme:
+		return crc64.New(crc64NVMETable)
+	case types.ChecksumAlgorithmSha1:
+		return sha1.New()
+	case types.ChecksumAlgorithmSha256:
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// computeChecksum returns the base64 checksum of data.
+func computeChecksum(algorithm types.ChecksumAlgorithm, data []byte) string {
+	h := newHash(algorithm)
+	if h == nil {
+		return ""
+	}
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// checksumFields returns value in the response field for algorithm.
+func checksumFields(algorithm types.ChecksumAlgorithm, value string) (crc32, crc32c, crc64nvme, sha1, sha256 *string) {
+	if value == "" {
+		return
+	}
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		crc32 = aws.String(value)
+	case types.ChecksumAlgorithmCrc32c:
+		crc32c = aws.String(value)
+	case types.ChecksumAlgorithmCrc64nvme:
+		crc64nvme = aws.String(value)
+	case types.ChecksumAlgorithmSha1:
+		sha1 = aws.String(value)
+	case types.ChecksumAlgorithmSha256:
+		sha256 = aws.String(value)
+	}
+	return
+}
+
+// checksumInput holds the checksum fields of a request.
+type checksumInput struct {
+	algorithm                              types.ChecksumAlgorithm
+	crc32, crc32c, crc64nvme, sha1, sha256 *string
+}
+
+// value returns the algorithm and value of the checksum that was sent.
+func (c checksumInput) value() (types.ChecksumAlgorithm, string) {
+	switch {
+	case c.crc32 != nil:
+		return types.ChecksumAlgorithmCrc32, *c.crc32
+	case c.crc32c != nil:
+		return types.ChecksumAlgorithmCrc32c, *c.crc32c
+	case c.crc64nvme != nil:
+		return types.ChecksumAlgorithmCrc64nvme, *c.crc64nvme
+	case c.sha1 != nil:
+		return types.ChecksumAlgorithmSha1, *c.sha1
+	case c.sha256 != nil:
+		return types.ChecksumAlgorithmSha256, *c.sha256
+	}
+	return c.algorithm, ""
+}
+
+// verify checks a sent checksum against data and returns the algorithm and
+// the checksum to store, if any.
+func (c checksumInput) verify(op string, data []byte) (types.ChecksumAlgorithm, string, error) {
+	algorithm, given := c.value()
+	if algorithm == "" {
+		return "", "", nil
+	}
+	if newHash(algorithm) == nil {
+		return "", "", errorf(op, http.StatusBadRequest, "InvalidRequest", "unsupported checksum algorithm %s", algorithm)
+	}
+	actual := computeChecksum(algorithm, data)
+	if given != "" && given != actual {
+		return "", "", errorf(op, http.StatusBadRequest, "BadDigest", "the %s you specified did not match the calculated checksum", algorithm)
+	}
+	return algorithm, actual, nil
+}
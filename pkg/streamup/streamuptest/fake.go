@@ -0,0 +1,816 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package streamuptest provides an in-memory S3 implementation for testing
+// code built on streamup without network access.
+//
+//	fake := streamuptest.NewFake()
+//	fake.ThrottlePart(2, 1)
+//	client, _ := streamup.NewClient(streamup.ClientConfig{API: fake})
+package streamuptest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Object is a stored object.
+type Object struct {
+	Bucket string
+	Key    string
+	Data   []byte
+	ETag   string // Quoted, as S3 returns it
+	Parts  int    // Number of parts, or 0 for objects written with PutObject
+
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	Metadata           map[string]string
+	Tagging            string
+	StorageClass       types.StorageClass
+	ACL                types.ObjectCannedACL
+
+	ServerSideEncryption types.ServerSideEncryption
+	SSEKMSKeyID          string
+	SSECustomerKeyMD5    string
+
+	ChecksumAlgorithm types.ChecksumAlgorithm
+	ChecksumType      types.ChecksumType
+	Checksum          string // Base64 object checksum ("-N" suffixed for composite)
+
+	LastModified time.Time
+}
+
+// Fake is an in-memory, multipart-aware implementation of streamup.S3API.
+// It is safe for concurrent use. Buckets exist implicitly.
+type Fake struct {
+	// MinPartSize is enforced for all but the last part on completion,
+	// as S3 does with 5 MiB (0 = no minimum).
+	MinPartSize int64
+
+	mu      sync.Mutex
+	objects map[string]*Object
+	uploads map[string]*upload
+	faults  []*Fault
+	calls   map[string]int
+	nextID  int
+}
+
+// upload is an in-progress multipart upload.
+type upload struct {
+	object    Object // Object fields from CreateMultipartUpload
+	id        string
+	initiated time.Time
+	parts     map[int32]*part
+}
+
+// part is an uploaded part.
+type part struct {
+	data     []byte
+	etag     string
+	checksum string
+	modified time.Time
+}
+
+// NewFake creates an empty fake.
+func NewFake() *Fake {
+	return &Fake{
+		objects: make(map[string]*Object),
+		uploads: make(map[string]*upload),
+		calls:   make(map[string]int),
+	}
+}
+
+// Object returns a copy of a stored object.
+func (f *Fake) Object(bucket, key string) (*Object, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[objectKey(bucket, key)]
+	if !ok {
+		return nil, false
+	}
+	c := *obj
+	return &c, true
+}
+
+// Uploads returns the IDs of multipart uploads that were neither completed
+// nor aborted.
+func (f *Fake) Uploads() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]string, 0, len(f.uploads))
+	for id := range f.uploads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Calls returns the number of calls made to an operation, including failed ones.
+func (f *Fake) Calls(op string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+func objectKey(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// CreateMultipartUpload starts a multipart upload.
+func (f *Fake) CreateMultipartUpload(ctx context.Context, in *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	const op = OpCreateMultipartUpload
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	checksumType := in.ChecksumType
+	if in.ChecksumAlgorithm != "" && checksumType == "" {
+		checksumType = types.ChecksumTypeComposite
+		if in.ChecksumAlgorithm == types.ChecksumAlgorithmCrc64nvme {
+			checksumType = types.ChecksumTypeFullObject
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	up := &upload{
+		id:        fmt.Sprintf("fake-upload-%d", f.nextID),
+		initiated: time.Now(),
+		parts:     make(map[int32]*part),
+		object: Object{
+			Bucket:               aws.ToString(in.Bucket),
+			Key:                  aws.ToString(in.Key),
+			ContentType:          aws.ToString(in.ContentType),
+			ContentEncoding:      aws.ToString(in.ContentEncoding),
+			ContentDisposition:   aws.ToString(in.ContentDisposition),
+			ContentLanguage:      aws.ToString(in.ContentLanguage),
+			CacheControl:         aws.ToString(in.CacheControl),
+			Metadata:             lowerKeys(in.Metadata),
+			Tagging:              aws.ToString(in.Tagging),
+			StorageClass:         in.StorageClass,
+			ACL:                  in.ACL,
+			ServerSideEncryption: in.ServerSideEncryption,
+			SSEKMSKeyID:          aws.ToString(in.SSEKMSKeyId),
+			SSECustomerKeyMD5:    aws.ToString(in.SSECustomerKeyMD5),
+			ChecksumAlgorithm:    in.ChecksumAlgorithm,
+			ChecksumType:         checksumType,
+		},
+	}
+	f.uploads[up.id] = up
+
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:               in.Bucket,
+		Key:                  in.Key,
+		UploadId:             aws.String(up.id),
+		ChecksumAlgorithm:    in.ChecksumAlgorithm,
+		ChecksumType:         checksumType,
+		ServerSideEncryption: in.ServerSideEncryption,
+		SSEKMSKeyId:          in.SSEKMSKeyId,
+	}, nil
+}
+
+// UploadPart stores a part of a multipart upload.
+func (f *Fake) UploadPart(ctx context.Context, in *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	const op = OpUploadPart
+	partNumber := aws.ToInt32(in.PartNumber)
+	if err := f.inject(op, partNumber, in.Body); err != nil {
+		return nil, err
+	}
+	data, err := readBody(ctx, in.Body)
+	if err != nil {
+		return nil, operationError(op, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	up, err := f.upload(op, in.UploadId, in.Bucket, in.Key)
+	if err != nil {
+		return nil, err
+	}
+	if partNumber < 1 || partNumber > 10000 {
+		return nil, errorf(op, http.StatusBadRequest, "InvalidArgument", "part number must be between 1 and 10000")
+	}
+	if err := checkCustomerKey(op, up.object.SSECustomerKeyMD5, in.SSECustomerKeyMD5); err != nil {
+		return nil, err
+	}
+	if err := checkContentMD5(op, in.ContentMD5, data); err != nil {
+		return nil, err
+	}
+
+	sum := checksumInput{in.ChecksumAlgorithm, in.ChecksumCRC32, in.ChecksumCRC32C, in.ChecksumCRC64NVME, in.ChecksumSHA1, in.ChecksumSHA256}
+	algorithm, checksum, err := sum.verify(op, data)
+	if err != nil {
+		return nil, err
+	}
+	if up.object.ChecksumAlgorithm != "" {
+		if algorithm != "" && algorithm != up.object.ChecksumAlgorithm {
+			return nil, errorf(op, http.StatusBadRequest, "InvalidRequest", "checksum algorithm %s does not match the upload's %s", algorithm, up.object.ChecksumAlgorithm)
+		}
+		algorithm = up.object.ChecksumAlgorithm
+		checksum = computeChecksum(algorithm, data)
+	}
+
+	p := &part{
+		data:     data,
+		etag:     etag(data, opaqueETag(up.object)),
+		checksum: checksum,
+		modified: time.Now(),
+	}
+	up.parts[partNumber] = p
+
+	out := &s3.UploadPartOutput{
+		ETag:                 aws.String(p.etag),
+		ServerSideEncryption: up.object.ServerSideEncryption,
+		SSEKMSKeyId:          optionalString(up.object.SSEKMSKeyID),
+	}
+	out.ChecksumCRC32, out.ChecksumCRC32C, out.ChecksumCRC64NVME, out.ChecksumSHA1, out.ChecksumSHA256 = checksumFields(algorithm, checksum)
+	return out, nil
+}
+
+// CompleteMultipartUpload assembles the listed parts into an object.
+func (f *Fake) CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	const op = OpCompleteMultipartUpload
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	up, err := f.upload(op, in.UploadId, in.Bucket, in.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCustomerKey(op, up.object.SSECustomerKeyMD5, in.SSECustomerKeyMD5); err != nil {
+		return nil, err
+	}
+	if in.MultipartUpload == nil || len(in.MultipartUpload.Parts) == 0 {
+		return nil, errorf(op, http.StatusBadRequest, "MalformedXML", "the upload must list at least one part")
+	}
+
+	listed := in.MultipartUpload.Parts
+	var data []byte
+	var digests []byte
+	var checksums []byte
+	var last int32
+	for i, lp := range listed {
+		n := aws.ToInt32(lp.PartNumber)
+		if n <= last {
+			return nil, errorf(op, http.StatusBadRequest, "InvalidPartOrder", "parts must be listed in ascending order")
+		}
+		last = n
+
+		p, ok := up.parts[n]
+		if !ok || strings.Trim(aws.ToString(lp.ETag), `"`) != strings.Trim(p.etag, `"`) {
+			return nil, errorf(op, http.StatusBadRequest, "InvalidPart", "part %d was not found or its ETag does not match", n)
+		}
+		if f.MinPartSize > 0 && i < len(listed)-1 && int64(len(p.data)) < f.MinPartSize {
+			return nil, errorf(op, http.StatusBadRequest, "EntityTooSmall", "part %d is smaller than the minimum part size", n)
+		}
+		if up.object.ChecksumType == types.ChecksumTypeComposite {
+			_, listedSum := checksumInput{"", lp.ChecksumCRC32, lp.ChecksumCRC32C, lp.ChecksumCRC64NVME, lp.ChecksumSHA1, lp.ChecksumSHA256}.value()
+			if listedSum != "" && listedSum != p.checksum {
+				return nil, errorf(op, http.StatusBadRequest, "InvalidPart", "checksum for part %d does not match", n)
+			}
+			raw, _ := base64.StdEncoding.DecodeString(p.checksum)
+			checksums = append(checksums, raw...)
+		}
+
+		data = append(data, p.data...)
+		digest, _ := hex.DecodeString(strings.Trim(p.etag, `"`))
+		digests = append(digests, digest...)
+	}
+
+	if err := f.checkPreconditions(op, objectKey(up.object.Bucket, up.object.Key), in.IfNoneMatch, in.IfMatch); err != nil {
+		return nil, err
+	}
+
+	obj := up.object
+	obj.Data = data
+	obj.Parts = len(listed)
+	obj.LastModified = time.Now()
+	sum := md5.Sum(digests)
+	obj.ETag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(listed))
+
+	switch obj.ChecksumType {
+	case types.ChecksumTypeComposite:
+		h := newHash(obj.ChecksumAlgorithm)
+		h.Write(checksums)
+		obj.Checksum = fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(listed))
+	case types.ChecksumTypeFullObject:
+		obj.Checksum = computeChecksum(obj.ChecksumAlgorithm, data)
+		_, given := checksumInput{"", in.ChecksumCRC32, in.ChecksumCRC32C, in.ChecksumCRC64NVME, in.ChecksumSHA1, in.ChecksumSHA256}.value()
+		if given != "" && given != obj.Checksum {
+			return nil, errorf(op, http.StatusBadRequest, "BadDigest", "the full object checksum does not match")
+		}
+	}
+
+	f.objects[objectKey(obj.Bucket, obj.Key)] = &obj
+	delete(f.uploads, up.id)
+	if err := f.loseResponse(op); err != nil {
+		return nil, err
+	}
+
+	out := &s3.CompleteMultipartUploadOutput{
+		Bucket:               in.Bucket,
+		Key:                  in.Key,
+		ETag:                 aws.String(obj.ETag),
+		ChecksumType:         obj.ChecksumType,
+		ServerSideEncryption: obj.ServerSideEncryption,
+		SSEKMSKeyId:          optionalString(obj.SSEKMSKeyID),
+	}
+	out.ChecksumCRC32, out.ChecksumCRC32C, out.ChecksumCRC64NVME, out.ChecksumSHA1, out.ChecksumSHA256 = checksumFields(obj.ChecksumAlgorithm, obj.Checksum)
+	return out, nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts.
+func (f *Fake) AbortMultipartUpload(ctx context.Context, in *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	const op = OpAbortMultipartUpload
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	up, err := f.upload(op, in.UploadId, in.Bucket, in.Key)
+	if err != nil {
+		return nil, err
+	}
+	delete(f.uploads, up.id)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// ListParts lists the parts of a multipart upload.
+func (f *Fake) ListParts(ctx context.Context, in *s3.ListPartsInput, _ ...func(*s3.Options)) (*s3.ListPartsOutput, error) {
+	const op = OpListParts
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	up, err := f.upload(op, in.UploadId, in.Bucket, in.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	marker, _ := strconv.Atoi(aws.ToString(in.PartNumberMarker))
+	var numbers []int32
+	for n := range up.parts {
+		if int(n) > marker {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	limit := int(aws.ToInt32(in.MaxParts))
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	truncated := len(numbers) > limit
+	if truncated {
+		numbers = numbers[:limit]
+	}
+
+	out := &s3.ListPartsOutput{
+		Bucket:            in.Bucket,
+		Key:               in.Key,
+		UploadId:          in.UploadId,
+		IsTruncated:       aws.Bool(truncated),
+		ChecksumAlgorithm: up.object.ChecksumAlgorithm,
+		ChecksumType:      up.object.ChecksumType,
+	}
+	for _, n := range numbers {
+		p := up.parts[n]
+		tp := types.Part{
+			PartNumber:   aws.Int32(n),
+			ETag:         aws.String(p.etag),
+			Size:         aws.Int64(int64(len(p.data))),
+			LastModified: aws.Time(p.modified),
+		}
+		tp.ChecksumCRC32, tp.ChecksumCRC32C, tp.ChecksumCRC64NVME, tp.ChecksumSHA1, tp.ChecksumSHA256 = checksumFields(up.object.ChecksumAlgorithm, p.checksum)
+		out.Parts = append(out.Parts, tp)
+	}
+	if truncated {
+		out.NextPartNumberMarker = aws.String(strconv.Itoa(int(numbers[len(numbers)-1])))
+	}
+	return out, nil
+}
+
+// ListMultipartUploads lists in-progress multipart uploads in a bucket.
+func (f *Fake) ListMultipartUploads(ctx context.Context, in *s3.ListMultipartUploadsInput, _ ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error) {
+	const op = OpListMultipartUploads
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var uploads []*upload
+	for _, up := range f.uploads {
+		if up.object.Bucket == aws.ToString(in.Bucket) &&
+			strings.HasPrefix(up.object.Key, aws.ToString(in.Prefix)) &&
+			up.object.Key > aws.ToString(in.KeyMarker) {
+			uploads = append(uploads, up)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].object.Key != uploads[j].object.Key {
+			return uploads[i].object.Key < uploads[j].object.Key
+		}
+		return uploads[i].initiated.Before(uploads[j].initiated)
+	})
+
+	limit := int(aws.ToInt32(in.MaxUploads))
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	truncated := len(uploads) > limit
+	if truncated {
+		uploads = uploads[:limit]
+	}
+
+	out := &s3.ListMultipartUploadsOutput{
+		Bucket:      in.Bucket,
+		Prefix:      in.Prefix,
+		IsTruncated: aws.Bool(truncated),
+	}
+	for _, up := range uploads {
+		out.Uploads = append(out.Uploads, types.MultipartUpload{
+			Key:               aws.String(up.object.Key),
+			UploadId:          aws.String(up.id),
+			Initiated:         aws.Time(up.initiated),
+			StorageClass:      up.object.StorageClass,
+			ChecksumAlgorithm: up.object.ChecksumAlgorithm,
+			ChecksumType:      up.object.ChecksumType,
+		})
+	}
+	if truncated {
+		out.NextKeyMarker = aws.String(uploads[len(uploads)-1].object.Key)
+	}
+	return out, nil
+}
+
+// PutObject stores an object in a single request.
+func (f *Fake) PutObject(ctx context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	const op = OpPutObject
+	if err := f.inject(op, 0, in.Body); err != nil {
+		return nil, err
+	}
+	data, err := readBody(ctx, in.Body)
+	if err != nil {
+		return nil, operationError(op, err)
+	}
+	if in.ContentLength != nil && *in.ContentLength != int64(len(data)) {
+		return nil, errorf(op, http.StatusBadRequest, "IncompleteBody", "received %d of %d bytes", len(data), *in.ContentLength)
+	}
+	if err := checkContentMD5(op, in.ContentMD5, data); err != nil {
+		return nil, err
+	}
+	sum := checksumInput{in.ChecksumAlgorithm, in.ChecksumCRC32, in.ChecksumCRC32C, in.ChecksumCRC64NVME, in.ChecksumSHA1, in.ChecksumSHA256}
+	algorithm, checksum, err := sum.verify(op, data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := objectKey(aws.ToString(in.Bucket), aws.ToString(in.Key))
+	if err := f.checkPreconditions(op, key, in.IfNoneMatch, in.IfMatch); err != nil {
+		return nil, err
+	}
+
+	obj := &Object{
+		Bucket:               aws.ToString(in.Bucket),
+		Key:                  aws.ToString(in.Key),
+		Data:                 data,
+		ContentType:          aws.ToString(in.ContentType),
+		ContentEncoding:      aws.ToString(in.ContentEncoding),
+		ContentDisposition:   aws.ToString(in.ContentDisposition),
+		ContentLanguage:      aws.ToString(in.ContentLanguage),
+		CacheControl:         aws.ToString(in.CacheControl),
+		Metadata:             lowerKeys(in.Metadata),
+		Tagging:              aws.ToString(in.Tagging),
+		StorageClass:         in.StorageClass,
+		ACL:                  in.ACL,
+		ServerSideEncryption: in.ServerSideEncryption,
+		SSEKMSKeyID:          aws.ToString(in.SSEKMSKeyId),
+		SSECustomerKeyMD5:    aws.ToString(in.SSECustomerKeyMD5),
+		ChecksumAlgorithm:    algorithm,
+		Checksum:             checksum,
+		LastModified:         time.Now(),
+	}
+	if algorithm != "" {
+		obj.ChecksumType = types.ChecksumTypeFullObject
+	}
+	obj.ETag = etag(data, opaqueETag(*obj))
+	f.objects[key] = obj
+
+	out := &s3.PutObjectOutput{
+		ETag:                 aws.String(obj.ETag),
+		ChecksumType:         obj.ChecksumType,
+		ServerSideEncryption: obj.ServerSideEncryption,
+		SSEKMSKeyId:          in.SSEKMSKeyId,
+		Size:                 aws.Int64(int64(len(data))),
+	}
+	out.ChecksumCRC32, out.ChecksumCRC32C, out.ChecksumCRC64NVME, out.ChecksumSHA1, out.ChecksumSHA256 = checksumFields(algorithm, checksum)
+	return out, nil
+}
+
+// HeadObject returns an object's metadata.
+func (f *Fake) HeadObject(ctx context.Context, in *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	const op = OpHeadObject
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[objectKey(aws.ToString(in.Bucket), aws.ToString(in.Key))]
+	if !ok {
+		return nil, errorf(op, http.StatusNotFound, "NotFound", "not found")
+	}
+	if err := checkCustomerKey(op, obj.SSECustomerKeyMD5, in.SSECustomerKeyMD5); err != nil {
+		return nil, err
+	}
+
+	out := &s3.HeadObjectOutput{
+		ContentLength:        aws.Int64(int64(len(obj.Data))),
+		ETag:                 aws.String(obj.ETag),
+		LastModified:         aws.Time(obj.LastModified),
+		ContentType:          optionalString(obj.ContentType),
+		ContentEncoding:      optionalString(obj.ContentEncoding),
+		ContentDisposition:   optionalString(obj.ContentDisposition),
+		ContentLanguage:      optionalString(obj.ContentLanguage),
+		CacheControl:         optionalString(obj.CacheControl),
+		Metadata:             copyMap(obj.Metadata),
+		StorageClass:         obj.StorageClass,
+		ServerSideEncryption: obj.ServerSideEncryption,
+		SSEKMSKeyId:          optionalString(obj.SSEKMSKeyID),
+		SSECustomerKeyMD5:    optionalString(obj.SSECustomerKeyMD5),
+	}
+	if obj.Parts > 0 {
+		out.PartsCount = aws.Int32(int32(obj.Parts))
+	}
+	if in.ChecksumMode == types.ChecksumModeEnabled {
+		out.ChecksumType = obj.ChecksumType
+		out.ChecksumCRC32, out.ChecksumCRC32C, out.ChecksumCRC64NVME, out.ChecksumSHA1, out.ChecksumSHA256 = checksumFields(obj.ChecksumAlgorithm, obj.Checksum)
+	}
+	return out, nil
+}
+
+// GetObject returns an object's data and metadata.
+func (f *Fake) GetObject(ctx context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	const op = OpGetObject
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[objectKey(aws.ToString(in.Bucket), aws.ToString(in.Key))]
+	if !ok {
+		return nil, errorf(op, http.StatusNotFound, "NoSuchKey", "the specified key does not exist")
+	}
+	if err := checkCustomerKey(op, obj.SSECustomerKeyMD5, in.SSECustomerKeyMD5); err != nil {
+		return nil, err
+	}
+
+	out := &s3.GetObjectOutput{
+		Body:                 io.NopCloser(bytes.NewReader(obj.Data)),
+		ContentLength:        aws.Int64(int64(len(obj.Data))),
+		ETag:                 aws.String(obj.ETag),
+		LastModified:         aws.Time(obj.LastModified),
+		ContentType:          optionalString(obj.ContentType),
+		ContentEncoding:      optionalString(obj.ContentEncoding),
+		ContentDisposition:   optionalString(obj.ContentDisposition),
+		ContentLanguage:      optionalString(obj.ContentLanguage),
+		CacheControl:         optionalString(obj.CacheControl),
+		Metadata:             copyMap(obj.Metadata),
+		StorageClass:         obj.StorageClass,
+		ServerSideEncryption: obj.ServerSideEncryption,
+		SSEKMSKeyId:          optionalString(obj.SSEKMSKeyID),
+		SSECustomerKeyMD5:    optionalString(obj.SSECustomerKeyMD5),
+	}
+	if obj.Parts > 0 {
+		out.PartsCount = aws.Int32(int32(obj.Parts))
+	}
+	if in.ChecksumMode == types.ChecksumModeEnabled {
+		out.ChecksumType = obj.ChecksumType
+		out.ChecksumCRC32, out.ChecksumCRC32C, out.ChecksumCRC64NVME, out.ChecksumSHA1, out.ChecksumSHA256 = checksumFields(obj.ChecksumAlgorithm, obj.Checksum)
+	}
+	return out, nil
+}
+
+// ListObjectsV2 lists objects in a bucket in key order.
+func (f *Fake) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	const op = OpListObjectsV2
+	if err := f.inject(op, 0, nil); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The continuation token is the last key returned
+	after := aws.ToString(in.StartAfter)
+	if token := aws.ToString(in.ContinuationToken); token > after {
+		after = token
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var objects []*Object
+	for _, obj := range f.objects {
+		if obj.Bucket == aws.ToString(in.Bucket) &&
+			strings.HasPrefix(obj.Key, aws.ToString(in.Prefix)) &&
+			obj.Key > after {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	limit := int(aws.ToInt32(in.MaxKeys))
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	truncated := len(objects) > limit
+	if truncated {
+		objects = objects[:limit]
+	}
+
+	out := &s3.ListObjectsV2Output{
+		Name:              in.Bucket,
+		Prefix:            in.Prefix,
+		ContinuationToken: in.ContinuationToken,
+		IsTruncated:       aws.Bool(truncated),
+		KeyCount:          aws.Int32(int32(len(objects))),
+		MaxKeys:           aws.Int32(int32(limit)),
+	}
+	for _, obj := range objects {
+		out.Contents = append(out.Contents, types.Object{
+			Key:          aws.String(obj.Key),
+			Size:         aws.Int64(int64(len(obj.Data))),
+			ETag:         aws.String(obj.ETag),
+			LastModified: aws.Time(obj.LastModified),
+			StorageClass: types.ObjectStorageClass(obj.StorageClass),
+		})
+	}
+	if truncated {
+		out.NextContinuationToken = aws.String(objects[len(objects)-1].Key)
+	}
+	return out, nil
+}
+
+// upload looks up a multipart upload. The caller must hold f.mu.
+func (f *Fake) upload(op string, uploadID, bucket, key *string) (*upload, error) {
+	up, ok := f.uploads[aws.ToString(uploadID)]
+	if !ok || up.object.Bucket != aws.ToString(bucket) || up.object.Key != aws.ToString(key) {
+		return nil, errorf(op, http.StatusNotFound, "NoSuchUpload", "the specified upload does not exist")
+	}
+	return up, nil
+}
+
+// checkPreconditions applies If-None-Match and If-Match to a write. The
+// caller must hold f.mu.
+func (f *Fake) checkPreconditions(op, key string, ifNoneMatch, ifMatch *string) error {
+	existing, exists := f.objects[key]
+	if aws.ToString(ifNoneMatch) == "*" && exists {
+		return errorf(op, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+	}
+	if ifMatch != nil {
+		if !exists {
+			return errorf(op, http.StatusNotFound, "NoSuchKey", "the specified key does not exist")
+		}
+		if strings.Trim(*ifMatch, `"`) != strings.Trim(existing.ETag, `"`) {
+			return errorf(op, http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the pre-conditions you specified did not hold")
+		}
+	}
+	return nil
+}
+
+// checkCustomerKey requires the SSE-C key an object was written with.
+func checkCustomerKey(op, stored string, given *string) error {
+	if stored != aws.ToString(given) {
+		return errorf(op, http.StatusBadRequest, "InvalidRequest", "the SSE-C key does not match the object's")
+	}
+	return nil
+}
+
+// checkContentMD5 verifies a Content-MD5 header, if one was sent.
+func checkContentMD5(op string, contentMD5 *string, data []byte) error {
+	if contentMD5 == nil {
+		return nil
+	}
+	sum := md5.Sum(data)
+	if *contentMD5 != base64.StdEncoding.EncodeToString(sum[:]) {
+		return errorf(op, http.StatusBadRequest, "BadDigest", "the Content-MD5 you specified did not match what we received")
+	}
+	return nil
+}
+
+// readBody reads a request body, which may be nil.
+func readBody(ctx context.Context, body io.Reader) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return []byte{}, nil
+	}
+	return io.ReadAll(body)
+}
+
+// etag returns the quoted ETag for data. Objects encrypted with SSE-KMS or
+// SSE-C get an ETag that is not the MD5 of their data, as on S3.
+func etag(data []byte, opaque bool) string {
+	if opaque {
+		data = append([]byte("opaque:"), data...)
+	}
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func opaqueETag(obj Object) bool {
+	return obj.SSECustomerKeyMD5 != "" ||
+		obj.ServerSideEncryption == types.ServerSideEncryptionAwsKms ||
+		obj.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse
+}
+
+func lowerKeys(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
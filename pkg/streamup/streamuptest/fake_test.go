@@ -0,0 +1,286 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamuptest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// errorCode returns the S3 error code and HTTP status of err.
+func errorCode(err error) (string, int) {
+	var apiErr smithy.APIError
+	var respErr *smithyhttp.ResponseError
+	if !errors.As(err, &apiErr) || !errors.As(err, &respErr) {
+		return "", 0
+	}
+	return apiErr.ErrorCode(), respErr.HTTPStatusCode()
+}
+
+// multipart uploads parts and returns the upload ID and completed parts.
+func multipart(t *testing.T, f *Fake, parts ...[]byte) (string, []types.CompletedPart) {
+	t.Helper()
+	ctx := context.Background()
+
+	created, err := f.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:            aws.String("b"),
+		Key:               aws.String("k"),
+		ChecksumAlgorithm: types.ChecksumAlgorithmCrc32,
+	})
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload() error = %v", err)
+	}
+
+	var completed []types.CompletedPart
+	for i, data := range parts {
+		out, err := f.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String("b"),
+			Key:        aws.String("k"),
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(int32(i + 1)),
+			Body:       bytes.NewReader(data),
+		})
+		if err != nil {
+			t.Fatalf("UploadPart(%d) error = %v", i+1, err)
+		}
+		completed = append(completed, types.CompletedPart{
+			PartNumber:    aws.Int32(int32(i + 1)),
+			ETag:          out.ETag,
+			ChecksumCRC32: out.ChecksumCRC32,
+		})
+	}
+	return *created.UploadId, completed
+}
+
+func TestFake_Multipart(t *testing.T) {
+	f := NewFake()
+	ctx := context.Background()
+	id, parts := multipart(t, f, []byte("hello "), []byte("world"))
+
+	out, err := f.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String("b"),
+		Key:             aws.String("k"),
+		UploadId:        aws.String(id),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+	if !strings.HasSuffix(aws.ToString(out.ETag), `-2"`) {
+		t.Errorf("ETag = %s, want multipart ETag", aws.ToString(out.ETag))
+	}
+	if !strings.HasSuffix(aws.ToString(out.ChecksumCRC32), "-2") {
+		t.Errorf("ChecksumCRC32 = %s, want composite checksum", aws.ToString(out.ChecksumCRC32))
+	}
+
+	obj, ok := f.Object("b", "k")
+	if !ok || string(obj.Data) != "hello world" {
+		t.Fatalf("Object() = %v, %v", obj, ok)
+	}
+	if len(f.Uploads()) != 0 {
+		t.Error("completed upload is still in progress")
+	}
+}
+
+func TestFake_CompleteValidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		min    int64
+		modify func(parts []types.CompletedPart) []types.CompletedPart
+		code   string
+	}{
+		{
+			name:   "wrong ETag",
+			modify: func(p []types.CompletedPart) []types.CompletedPart { p[0].ETag = aws.String(`"x"`); return p },
+			code:   "InvalidPart",
+		},
+		{
+			name:   "out of order",
+			modify: func(p []types.CompletedPart) []types.CompletedPart { return []types.CompletedPart{p[1], p[0]} },
+			code:   "InvalidPartOrder",
+		},
+		{
+			name:   "part too small",
+			min:    100,
+			modify: func(p []types.CompletedPart) []types.CompletedPart { return p },
+			code:   "EntityTooSmall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFake()
+			f.MinPartSize = tt.min
+			id, parts := multipart(t, f, []byte("a"), []byte("b"))
+
+			_, err := f.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          aws.String("b"),
+				Key:             aws.String("k"),
+				UploadId:        aws.String(id),
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: tt.modify(parts)},
+			})
+			if code, status := errorCode(err); code != tt.code || status != 400 {
+				t.Errorf("error = %v, want %s (400)", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestFake_Faults(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	f.ThrottlePart(2, 1)
+	f.DropConnection(1, 1)
+	f.FailComplete(1)
+
+	created, _ := f.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String("b"), Key: aws.String("k")})
+	upload := func(n int32) error {
+		_, err := f.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String("b"),
+			Key:        aws.String("k"),
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(n),
+			Body:       bytes.NewReader([]byte("data")),
+		})
+		return err
+	}
+
+	var netErr net.Error
+	if err := upload(1); !errors.As(err, &netErr) {
+		t.Errorf("part 1 error = %v, want connection error", err)
+	}
+	if err := upload(2); err == nil {
+		t.Error("part 2 should be throttled")
+	} else if code, status := errorCode(err); code != "SlowDown" || status != 503 {
+		t.Errorf("part 2 error = %s (%d), want SlowDown (503)", code, status)
+	}
+
+	// Faults are used up
+	if err := upload(1); err != nil {
+		t.Errorf("part 1 retry error = %v", err)
+	}
+	if err := upload(2); err != nil {
+		t.Errorf("part 2 retry error = %v", err)
+	}
+
+	complete := &s3.CompleteMultipartUploadInput{Bucket: aws.String("b"), Key: aws.String("k"), UploadId: created.UploadId}
+	if _, err := f.CompleteMultipartUpload(ctx, complete); err == nil {
+		t.Error("first CompleteMultipartUpload should fail")
+	}
+	if f.Calls(OpUploadPart) != 4 || f.Calls(OpCompleteMultipartUpload) != 1 {
+		t.Errorf("Calls = %d UploadPart, %d Complete", f.Calls(OpUploadPart), f.Calls(OpCompleteMultipartUpload))
+	}
+}
+
+func TestFake_LoseCompleteResponse(t *testing.T) {
+	f := NewFake()
+	f.LoseCompleteResponse(1)
+	ctx := context.Background()
+	id, parts := multipart(t, f, []byte("hello "), []byte("world"))
+
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String("b"),
+		Key:             aws.String("k"),
+		UploadId:        aws.String(id),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	}
+	var netErr net.Error
+	if _, err := f.CompleteMultipartUpload(ctx, complete); !errors.As(err, &netErr) {
+		t.Errorf("CompleteMultipartUpload() error = %v, want connection error", err)
+	}
+	if obj, ok := f.Object("b", "k"); !ok || string(obj.Data) != "hello world" {
+		t.Error("the upload should complete even though its response was lost")
+	}
+
+	// A retry finds the upload gone
+	if _, err := f.CompleteMultipartUpload(ctx, complete); err == nil {
+		t.Error("retried CompleteMultipartUpload should fail")
+	} else if code, _ := errorCode(err); code != "NoSuchUpload" {
+		t.Errorf("retry error = %s, want NoSuchUpload", code)
+	}
+}
+
+func TestFake_PutObjectChecks(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+
+	put := func(in *s3.PutObjectInput) error {
+		in.Bucket = aws.String("b")
+		in.Key = aws.String("k")
+		in.Body = bytes.NewReader([]byte("data"))
+		_, err := f.PutObject(ctx, in)
+		return err
+	}
+
+	if err := put(&s3.PutObjectInput{ChecksumSHA256: aws.String("bad")}); err == nil {
+		t.Error("bad checksum should be rejected")
+	}
+	if err := put(&s3.PutObjectInput{IfNoneMatch: aws.String("*")}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	if code, status := errorCode(put(&s3.PutObjectInput{IfNoneMatch: aws.String("*")})); code != "PreconditionFailed" || status != 412 {
+		t.Errorf("overwrite error = %s (%d), want PreconditionFailed (412)", code, status)
+	}
+
+	// SSE-C objects need the key to be read
+	if err := put(&s3.PutObjectInput{SSECustomerKeyMD5: aws.String("md5")}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	if _, err := f.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("k")}); err == nil {
+		t.Error("HeadObject without the SSE-C key should fail")
+	}
+}
+
+func TestFake_ListObjectsV2(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	for _, key := range []string{"a/1", "a/2", "a/3", "b/1"} {
+		if _, err := f.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String("b"), Key: aws.String(key)}); err != nil {
+			t.Fatalf("PutObject() error = %v", err)
+		}
+	}
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(f, &s3.ListObjectsV2Input{
+		Bucket:  aws.String("b"),
+		Prefix:  aws.String("a/"),
+		MaxKeys: aws.Int32(2),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			t.Fatalf("NextPage() error = %v", err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, *obj.Key)
+		}
+	}
+
+	if strings.Join(keys, ",") != "a/1,a/2,a/3" {
+		t.Errorf("keys = %v", keys)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamuptest
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Operation names, for Fault.Operation and Fake.Calls.
+const (
+	OpCreateMultipartUpload   = "CreateMultipartUpload"
+	OpUploadPart              = "UploadPart"
+	OpCompleteMultipartUpload = "CompleteMultipartUpload"
+	OpAbortMultipartUpload    = "AbortMultipartUpload"
+	OpListParts               = "ListParts"
+	OpListMultipartUploads    = "ListMultipartUploads"
+	OpPutObject               = "PutObject"
+	OpHeadObject              = "HeadObject"
+	OpGetObject               = "GetObject"
+	OpListObjectsV2           = "ListObjectsV2"
+)
+
+// Fault makes calls to an operation fail.
+type Fault struct {
+	Operation  string // Operation to fail (see the Op constants)
+	PartNumber int32  // Only fail this part (UploadPart only, 0 = any part)
+	Times      int    // Number of calls to fail (0 = every call)
+
+	// Err is returned from the failing calls. A nil Err drops the
+	// connection part way through the request body instead.
+	Err error
+
+	// LoseResponse lets the call succeed and then fails it with Err (or a
+	// dropped connection), as when the response is lost on the way back.
+	// Only CompleteMultipartUpload supports it.
+	LoseResponse bool
+}
+
+// matches reports whether the fault applies to a call, before the call
+// (lost is false) or after it succeeded (lost is true).
+func (f *Fault) matches(op string, partNumber int32, lost bool) bool {
+	if f.Operation != op || f.LoseResponse != lost {
+		return false
+	}
+	return f.PartNumber == 0 || f.PartNumber == partNumber
+}
+
+// APIError builds a service error as the SDK returns it, with an HTTP status,
+// an S3 error code and a request ID.
+func APIError(status int, code, message string) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{
+				StatusCode: status,
+				Header:     http.Header{},
+			}},
+			Err: &smithy.GenericAPIError{Code: code, Message: message},
+		},
+		RequestID: "FAKE-REQUEST-ID",
+	}
+}
+
+// ErrSlowDown is the error S3 returns when throttling requests.
+func ErrSlowDown() error {
+	return APIError(http.StatusServiceUnavailable, "SlowDown", "Please reduce your request rate.")
+}
+
+// ErrInternal is S3's generic retryable server error.
+func ErrInternal() error {
+	return APIError(http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.")
+}
+
+// ErrConnectionReset is returned when a fault drops the connection.
+func ErrConnectionReset() error {
+	return &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+}
+
+// AddFault registers a fault. Faults are checked in the order they were added.
+func (f *Fake) AddFault(fault Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, &fault)
+}
+
+// ThrottlePart makes the first times attempts to upload a part fail with SlowDown.
+func (f *Fake) ThrottlePart(partNumber int32, times int) {
+	f.AddFault(Fault{Operation: OpUploadPart, PartNumber: partNumber, Times: times, Err: ErrSlowDown()})
+}
+
+// DropConnection makes the first times attempts to upload a part lose the
+// connection after part of the body was sent.
+func (f *Fake) DropConnection(partNumber int32, times int) {
+	f.AddFault(Fault{Operation: OpUploadPart, PartNumber: partNumber, Times: times})
+}
+
+// FailComplete makes the first times calls to CompleteMultipartUpload fail
+// with InternalError (0 = every call).
+func (f *Fake) FailComplete(times int) {
+	f.AddFault(Fault{Operation: OpCompleteMultipartUpload, Times: times, Err: ErrInternal()})
+}
+
+// LoseCompleteResponse makes the first times calls to
+// CompleteMultipartUpload complete the upload but drop the connection before
+// the response arrives.
+func (f *Fake) LoseCompleteResponse(times int) {
+	f.AddFault(Fault{Operation: OpCompleteMultipartUpload, Times: times, LoseResponse: true})
+}
+
+// ClearFaults removes all registered faults.
+func (f *Fake) ClearFaults() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = nil
+}
+
+// inject counts a call and returns the error of the first matching fault,
+// consuming part of body when the fault drops the connection.
+func (f *Fake) inject(op string, partNumber int32, body io.Reader) error {
+	f.mu.Lock()
+	f.calls[op]++
+	fault := f.takeFault(op, partNumber, false)
+	f.mu.Unlock()
+
+	if fault == nil {
+		return nil
+	}
+	err := fault.Err
+	if err == nil {
+		if body != nil {
+			io.CopyN(io.Discard, body, 512)
+		}
+		err = ErrConnectionReset()
+	}
+	return operationError(op, err)
+}
+
+// loseResponse returns the error for a successful call whose response a
+// LoseResponse fault drops. f.mu must be held.
+func (f *Fake) loseResponse(op string) error {
+	fault := f.takeFault(op, 0, true)
+	if fault == nil {
+		return nil
+	}
+	err := fault.Err
+	if err == nil {
+		err = ErrConnectionReset()
+	}
+	return operationError(op, err)
+}
+
+// takeFault returns the first fault matching a call and uses up one of its
+// Times. f.mu must be held.
+func (f *Fake) takeFault(op string, partNumber int32, lost bool) *Fault {
+	for i, fault := range f.faults {
+		if !fault.matches(op, partNumber, lost) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				f.faults = append(f.faults[:i:i], f.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+// operationError wraps err the way the SDK wraps errors from an operation.
+func operationError(op string, err error) error {
+	return &smithy.OperationError{ServiceID: "S3", OperationName: op, Err: err}
+}
+
+// errorf builds a service error for an operation.
+func errorf(op string, status int, code, format string, args ...any) error {
+	return operationError(op, APIError(status, code, fmt.Sprintf(format, args...)))
+}
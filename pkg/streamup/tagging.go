@@ -0,0 +1,89 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 object tagging limits.
+const (
+	maxTags           = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// validateTags checks tags against the S3 tagging limits.
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxTags {
+		return &ValidationError{
+			Field:   "Tags",
+			Message: fmt.Sprintf("at most %d tags are allowed, got %d", maxTags, len(tags)),
+		}
+	}
+
+	for k, v := range tags {
+		if k == "" || utf8.RuneCountInString(k) > maxTagKeyLength {
+			return &ValidationError{
+				Field:   "Tags",
+				Message: fmt.Sprintf("tag key %q must be 1-%d characters", k, maxTagKeyLength),
+			}
+		}
+		if utf8.RuneCountInString(v) > maxTagValueLength {
+			return &ValidationError{
+				Field:   "Tags",
+				Message: fmt.Sprintf("value of tag %q must be at most %d characters", k, maxTagValueLength),
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeTags formats tags for the x-amz-tagging header: URL query parameters,
+// with spaces as %20 rather than '+', in a stable order.
+func encodeTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, escape(k)+"="+escape(tags[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// isCannedACL reports whether acl is one of the S3 canned ACLs.
+func isCannedACL(acl string) bool {
+	for _, v := range types.ObjectCannedACL("").Values() {
+		if string(v) == acl {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTags(t *testing.T) {
+	tags := map[string]string{
+		"team":        "data platform",
+		"cost-center": "a&b=c",
+		"path":        "backups/2025+",
+	}
+
+	want := "cost-center=a%26b%3Dc&path=backups%2F2025%2B&team=data%20platform"
+	if got := encodeTags(tags); got != want {
+		t.Errorf("encodeTags() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i < 11; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", map[string]string{"env": "prod"}, false},
+		{"too many", tooMany, true},
+		{"empty key", map[string]string{"": "v"}, true},
+		{"long key", map[string]string{strings.Repeat("k", 129): "v"}, true},
+		{"long value", map[string]string{"k": strings.Repeat("v", 257)}, true},
+		{"max lengths", map[string]string{strings.Repeat("k", 128): strings.Repeat("v", 256)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTags(tt.tags); (err != nil) != tt.wantErr {
+				t.Errorf("validateTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsCannedACL(t *testing.T) {
+	if !isCannedACL("bucket-owner-full-control") {
+		t.Error("isCannedACL(bucket-owner-full-control) = false, want true")
+	}
+	if isCannedACL("world-writable") {
+		t.Error("isCannedACL(world-writable) = true, want false")
+	}
+}
@@ -19,35 +19,55 @@ import (
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
-	"math"
+	"log/slog"
 	"net"
+	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // Uploader handles streaming multipart uploads to S3-compatible storage.
 type Uploader struct {
-	config     Config
-	s3Client   *s3.Client
-	partSize   int64
-	uploadID   string
-	ctx        context.Context
-	cancel     context.CancelFunc
+	config    Config
+	s3Client  S3API
+	s3Options []func(*s3.Options) // Per-request options, e.g. rate limiting
+	partSize  int64
+	schedule  PartSizeSchedule
+	pool      *bufferPool
+	uploadID  string
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	// Event reporting
+	logger  *slog.Logger
+	started time.Time // When Upload was called
+	etag    string    // ETag of the stored object
+
+	// Adaptive concurrency (nil unless Config.AdaptiveConcurrency is set)
+	concurrency *concurrencyController
+
+	// Decides which failed requests are retried (Config.RetryPolicy or the default)
+	retryPolicy RetryPolicy
+
+	// Speed of recent parts, for hedging (Config.HedgeMultiplier)
+	partRates partRates
 
 	// Progress tracking
 	bytesUploaded atomic.Int64
@@ -57,19 +77,50 @@ type Uploader struct {
 	checksum     string
 	checksumHash hash.Hash
 	checksumMu   sync.Mutex
+
+	// Server-side checksum tracking
+	objectChecksumHash hash.Hash // Whole-object hash for full_object checksums
+	serverChecksum     string    // Object checksum reported by the service
+
+	// Verification tracking (only used when Config.VerifyUpload is set)
+	bytesProduced int64            // Total size of all parts, including resumed ones
+	partDigests   map[int32][]byte // Part MD5s for calculating the multipart ETag
+	verification  *VerificationResult
+
+	// Server-side encryption request fields
+	sse sseHeaders
+
+	// Compression (empty unless Config.Compression applies to this object)
+	compression  string
+	originalSize int64        // Source size for metadata, or UnknownSize
+	sourceBytes  atomic.Int64 // Bytes read from the source, for progress
+
+	// Client-side encryption (nil unless Config.Encryption is set)
+	envelope *envelope
+
+	// Resume tracking (only used when Config.ResumeJournal is set)
+	journal *resumeJournal
+	resumed map[int32]journalPart
+
+	// Seekable source read by the workers directly (nil when streaming)
+	source *sectionSource
 }
 
 // part represents a chunk of data to be uploaded.
 type part struct {
-	number int32
-	data   []byte
+	number  int32
+	data    []byte            // Buffered data from the producer
+	section *io.SectionReader // Or the part of a seekable source to read
 }
 
 // completedPart represents an uploaded part with its ETag.
 type completedPart struct {
-	number int32
-	etag   string
-	err    error
+	number   int32
+	etag     string
+	size     int64
+	checksum string // Base64 server checksum of the part, if enabled
+	digest   []byte // MD5 of the part, if S3 uses it as the part's ETag
+	err      error
 }
 
 // New creates a new Uploader with the given configuration.
@@ -79,64 +130,143 @@ func New(cfg Config) (*Uploader, error) {
 		return nil, err
 	}
 
-	// Calculate optimal part size
-	partSize, err := CalculateOptimalPartSize(
-		cfg.FileSize,
-		cfg.MaxMemoryMB,
-		cfg.Workers,
-		cfg.QueueSize,
-		*cfg.ServiceLimits,
-	)
+	client, err := NewClient(ClientConfig{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+		Credentials:     cfg.Credentials,
+		AccountID:       cfg.AccountID,
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Logger:          cfg.Logger,
+	})
 	if err != nil {
-		return nil, err
+		return nil, &UploadError{Operation: "config creation", Err: err}
 	}
+	return client.NewUploader(cfg)
+}
 
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(cfg.Context)
+// NewUploader creates an Uploader that uses the client's connection. The
+// connection settings in cfg (credentials, account, endpoint and region) are
+// replaced by the client's.
+func (c *Client) NewUploader(cfg Config) (*Uploader, error) {
+	cfg.Credentials = c.credentials
+	cfg.AccountID = c.config.AccountID
+	cfg.Endpoint = c.config.Endpoint
+	cfg.Region = c.config.Region
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
-	// Create AWS credentials
-	creds := credentials.NewStaticCredentialsProvider(
-		cfg.AccessKeyID,
-		cfg.SecretAccessKey,
-		"",
-	)
-
-	// Create AWS config with custom User-Agent
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithCredentialsProvider(creds),
-		config.WithRegion(cfg.Region),
-		config.WithAppID(UserAgent()),
-	)
+	sse, err := newSSEHeaders(cfg.ServerSideEncryption, cfg.SSEKMSKeyID, cfg.SSEKMSEncryptionContext, cfg.SSECustomerKey)
 	if err != nil {
-		cancel()
-		return nil, &UploadError{Operation: "config creation", Err: err}
+		return nil, &ValidationError{Field: "SSEKMSEncryptionContext", Message: err.Error()}
+	}
+
+	// The compressed size is unknown up front, so parts follow a growing schedule
+	compression := resolveCompression(cfg.Compression, cfg.ContentType, cfg.ContentEncoding, cfg.Key)
+	originalSize := cfg.FileSize
+	if compression != "" {
+		cfg.ContentEncoding = compression
+		cfg.FileSize = UnknownSize
 	}
 
-	// Create S3 client with custom endpoint if provided
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		if cfg.Endpoint != "" {
-			o.BaseEndpoint = aws.String(cfg.Endpoint)
+	// Parts are cut from the encrypted stream, which is slightly larger
+	if cfg.Encryption != nil && cfg.FileSize != UnknownSize {
+		cfg.FileSize = encryptedSize(cfg.FileSize, cfg.Encryption.ChunkSize)
+	}
+
+	// Calculate part sizes: fixed for known sizes, growing for unknown sizes
+	var schedule PartSizeSchedule
+	if cfg.FileSize == UnknownSize {
+		var err error
+		schedule, err = CalculatePartSizeSchedule(
+			cfg.MaxMemoryMB,
+			cfg.Workers,
+			cfg.QueueSize,
+			*cfg.ServiceLimits,
+		)
+		if err != nil {
+			return nil, err
 		}
-		// R2 requires path-style addressing
-		if cfg.AccountID != "" {
-			o.UsePathStyle = false
+	} else {
+		partSize, err := CalculateOptimalPartSize(
+			cfg.FileSize,
+			cfg.MaxMemoryMB,
+			cfg.Workers,
+			cfg.QueueSize,
+			*cfg.ServiceLimits,
+		)
+		if err != nil {
+			return nil, err
 		}
-	})
+		schedule = PartSizeSchedule{InitialSize: partSize, MaxSize: partSize}
+	}
 
-	return &Uploader{
-		config:   cfg,
-		s3Client: s3Client,
-		partSize: partSize,
-		ctx:      ctx,
-		cancel:   cancel,
-	}, nil
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(cfg.Context)
+
+	// All workers share one limiter so the total rate stays under the limit
+	limiter := cfg.RateLimiter
+	if limiter == nil && cfg.MaxBytesPerSecond > 0 {
+		limiter = NewRateLimiter(cfg.MaxBytesPerSecond)
+	}
+	var s3Options []func(*s3.Options)
+	if limiter != nil {
+		s3Options = append(s3Options, func(o *s3.Options) {
+			o.HTTPClient = &rateLimitedHTTPClient{client: o.HTTPClient, limiter: limiter}
+		})
+	}
+
+	u := &Uploader{
+		config:       cfg,
+		s3Client:     c.s3Client,
+		s3Options:    s3Options,
+		partSize:     schedule.InitialSize,
+		schedule:     schedule,
+		pool:         newBufferPool(cfg.Workers + cfg.QueueSize),
+		sse:          sse,
+		compression:  compression,
+		originalSize: originalSize,
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       c.logger(cfg.Logger),
+	}
+	if cfg.AdaptiveConcurrency {
+		u.concurrency = newConcurrencyController(cfg.MinWorkers, cfg.Workers)
+	}
+	u.retryPolicy = cfg.RetryPolicy
+	if u.retryPolicy == nil {
+		u.retryPolicy = u.defaultRetryPolicy()
+	}
+
+	return u, nil
 }
 
 // Upload streams data from the reader to S3 using multipart upload.
-func (u *Uploader) Upload(reader io.Reader) error {
-	// Initialize multipart upload
-	if err := u.initializeMultipartUpload(); err != nil {
-		return err
+//
+// When Config.ResumeJournal is set, a failed upload is left in place rather
+// than aborted, and calling Upload again with the same journal and source
+// uploads only the parts that are missing. Otherwise a failed upload is
+// aborted; if that fails too, the error is an *OrphanedUploadError.
+func (u *Uploader) Upload(reader io.Reader) (err error) {
+	u.started = time.Now()
+
+	// Fail rather than hang if a pipe or network source stops sending
+	if u.config.SourceIdleTimeout > 0 && !isSeekable(reader) {
+		reader = newIdleReader(reader, u.config.SourceIdleTimeout)
+	}
+
+	// Stop reading as soon as a stream is longer than declared, and check
+	// seekable sources before starting the upload
+	if u.config.StrictSize && u.originalSize != UnknownSize {
+		if !isSeekable(reader) {
+			reader = &sizeLimitReader{reader: reader, expected: u.originalSize}
+		} else if err := u.checkSeekableSize(reader.(io.Seeker)); err != nil {
+			return err
+		}
 	}
 
 	// Initialize checksum calculation if enabled
@@ -148,12 +278,136 @@ func (u *Uploader) Upload(reader io.Reader) error {
 			u.checksumHash = sha256.New()
 		}
 	}
+	if u.config.ServerChecksumType == ServerChecksumFullObject {
+		u.objectChecksumHash = newServerChecksumHash(u.config.ServerChecksumAlgorithm)
+	}
+
+	// Compress before anything else sees the data (encryption comes after)
+	if u.compression != "" {
+		source := &countingReader{reader: reader, count: &u.sourceBytes}
+		compressed := compressReader(source, u.compression)
+		defer compressed.Close()
+		reader = compressed
+	}
 
-	// Ensure cleanup on error
+	// Generate a data key for this object (a resumed upload replaces it with the original)
+	if u.config.Encryption != nil {
+		env, err := newEnvelope(u.config.Encryption)
+		if err != nil {
+			return &UploadError{Operation: "generating data key", Err: err}
+		}
+		u.envelope = env
+	}
+
+	// Small objects are cheaper as a single PutObject than a multipart upload
+	threshold := u.config.SinglePartThreshold
+	if threshold > 0 && (u.config.FileSize == UnknownSize || u.config.FileSize <= threshold) {
+		// The read-ahead buffer counts against MaxMemoryMB like a part buffer.
+		// It has room for one byte more, to tell a source of exactly
+		// threshold bytes from a longer one.
+		buffer, err := u.pool.get(u.ctx, threshold+1)
+		if err != nil {
+			return err
+		}
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			u.pool.put(buffer)
+			return &UploadError{Operation: "reading data", Err: err}
+		}
+
+		// Hit EOF before filling the buffer: the whole source fits
+		if err != nil {
+			data := buffer[:n]
+			if u.envelope != nil {
+				if data, err = u.encryptSmallObject(data); err != nil {
+					u.pool.put(buffer)
+					return err
+				}
+				u.pool.put(buffer)
+				buffer = data
+			}
+			defer u.pool.put(buffer)
+			if err := u.checkSourceSize(u.sourceSize(int64(len(data)))); err != nil {
+				return err
+			}
+			return u.putSmallObject(data)
+		}
+
+		// Too big after all: replay what we read ahead of the rest of the stream
+		reader = io.MultiReader(u.pool.replay(buffer), reader)
+	}
+
+	// Seekable sources are read by the workers directly
+	source, err := u.newSectionSource(reader)
+	if err != nil {
+		return err
+	}
+	u.source = source
+
+	// Initialize (or resume) multipart upload
+	if u.config.ResumeJournal != "" {
+		if err := u.startResumableUpload(); err != nil {
+			return err
+		}
+	} else {
+		if err := u.initializeMultipartUpload(); err != nil {
+			return err
+		}
+	}
+
+	u.emit(UploadStarted{
+		Bucket:   u.config.Bucket,
+		Key:      u.config.Key,
+		UploadID: u.uploadID,
+		Size:     u.config.FileSize,
+		Resumed:  len(u.resumed) > 0,
+	})
+
+	// Encrypt after the upload is set up, once the data key is final
+	if u.envelope != nil {
+		reader = u.envelope.encryptReader(reader)
+	}
+
+	// Ensure cleanup on error (resumable uploads are kept for the next attempt)
 	var uploadErr error
 	defer func() {
-		if uploadErr != nil {
-			_ = u.Abort()
+		// A failed precondition will fail again, so never keep the upload for resuming
+		var precondErr *PreconditionFailedError
+		if errors.As(uploadErr, &precondErr) && u.journal != nil {
+			_ = u.journal.remove()
+			u.journal = nil
+		}
+
+		if uploadErr == nil {
+			if u.journal != nil {
+				_ = u.journal.remove()
+			}
+			return
+		}
+
+		aborted := UploadAborted{
+			Bucket:    u.config.Bucket,
+			Key:       u.config.Key,
+			UploadID:  u.uploadID,
+			Err:       uploadErr,
+			Resumable: u.journal != nil,
+		}
+		if u.journal != nil {
+			_ = u.journal.close()
+		} else {
+			aborted.AbortErr = u.Abort()
+		}
+		u.emit(aborted)
+
+		// Make sure the caller learns about parts left behind
+		if aborted.AbortErr != nil {
+			err = &OrphanedUploadError{
+				Bucket:   u.config.Bucket,
+				Key:      u.config.Key,
+				UploadID: u.uploadID,
+				Err:      uploadErr,
+				AbortErr: aborted.AbortErr,
+			}
 		}
 	}()
 
@@ -178,8 +432,22 @@ func (u *Uploader) Upload(reader io.Reader) error {
 		completedParts, collectorErr = u.collectResults(resultsChan)
 	}()
 
-	// Producer: read data and send parts
-	uploadErr = u.produceparts(reader, partsChan)
+	// Producer: read data and send parts. A seekable source is hashed in a
+	// separate pass while the workers read its parts.
+	hashErr := make(chan error, 1)
+	if u.source != nil {
+		if u.checksumHash != nil || u.objectChecksumHash != nil {
+			hashCtx, stopHashing := context.WithCancel(u.ctx)
+			defer stopHashing()
+			go func() { hashErr <- u.hashSource(hashCtx) }()
+		} else {
+			hashErr <- nil
+		}
+		uploadErr = u.producesections(partsChan)
+	} else {
+		hashErr <- nil
+		uploadErr = u.produceparts(reader, partsChan)
+	}
 	close(partsChan)
 
 	// Wait for workers to finish
@@ -189,11 +457,23 @@ func (u *Uploader) Upload(reader io.Reader) error {
 	// Wait for collector
 	collectorWg.Wait()
 
+	// A failed part cancels the producer, so report the part error first
+	if collectorErr != nil {
+		uploadErr = collectorErr
+		return uploadErr
+	}
 	if uploadErr != nil {
 		return uploadErr
 	}
-	if collectorErr != nil {
-		return collectorErr
+	if err := <-hashErr; err != nil {
+		uploadErr = err
+		return err
+	}
+
+	// Don't complete a truncated (or overlong) object in strict mode
+	if err := u.checkSourceSize(u.sourceSize(u.bytesProduced)); err != nil {
+		uploadErr = err
+		return err
 	}
 
 	// Complete the multipart upload
@@ -209,57 +489,455 @@ func (u *Uploader) Upload(reader io.Reader) error {
 		u.checksumMu.Unlock()
 	}
 
+	u.emit(UploadCompleted{
+		Bucket:   u.config.Bucket,
+		Key:      u.config.Key,
+		UploadID: u.uploadID,
+		ETag:     u.etag,
+		Size:     u.bytesProduced,
+		Parts:    len(completedParts),
+		Duration: time.Since(u.started),
+	})
+
+	// The upload is complete, so a mismatch is reported but never aborted
+	if u.config.VerifyUpload {
+		expectedETag := ""
+		if u.sse.etagIsMD5() {
+			expectedETag = multipartETag(u.partDigests)
+		}
+		return u.verifyUpload(u.bytesProduced, expectedETag)
+	}
+
+	return nil
+}
+
+// encryptSmallObject encrypts plaintext into a buffer from the pool.
+func (u *Uploader) encryptSmallObject(plaintext []byte) ([]byte, error) {
+	size := encryptedSize(int64(len(plaintext)), u.config.Encryption.ChunkSize)
+	buffer, err := u.pool.get(u.ctx, size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(u.envelope.encryptReader(bytes.NewReader(plaintext)), buffer); err != nil {
+		u.pool.put(buffer)
+		return nil, &UploadError{Operation: "encrypting data", Err: err}
+	}
+	return buffer, nil
+}
+
+// putSmallObject uploads data that fits in a single request and finalizes the checksum.
+func (u *Uploader) putSmallObject(data []byte) error {
+	if u.checksumHash != nil {
+		u.checksumHash.Write(data)
+	}
+
+	size := int64(len(data))
+	u.emit(UploadStarted{Bucket: u.config.Bucket, Key: u.config.Key, Size: size})
+	if err := u.putObject(data); err != nil {
+		// Nothing was stored, so there is nothing to abort
+		u.emit(UploadAborted{Bucket: u.config.Bucket, Key: u.config.Key, Err: err})
+		return err
+	}
+
+	if u.checksumHash != nil {
+		u.checksumMu.Lock()
+		u.checksum = hex.EncodeToString(u.checksumHash.Sum(nil))
+		u.checksumMu.Unlock()
+	}
+
+	u.emit(UploadCompleted{
+		Bucket:   u.config.Bucket,
+		Key:      u.config.Key,
+		ETag:     u.etag,
+		Size:     size,
+		Parts:    1,
+		Duration: time.Since(u.started),
+	})
+
+	if u.config.VerifyUpload {
+		expectedETag := ""
+		if u.sse.etagIsMD5() {
+			sum := md5.Sum(data)
+			expectedETag = hex.EncodeToString(sum[:])
+		}
+		return u.verifyUpload(int64(len(data)), expectedETag)
+	}
+
 	return nil
 }
 
 // initializeMultipartUpload starts a new multipart upload.
 func (u *Uploader) initializeMultipartUpload() error {
+	h := u.headers()
 	input := &s3.CreateMultipartUploadInput{
-		Bucket: aws.String(u.config.Bucket),
-		Key:    aws.String(u.config.Key),
+		Bucket:             aws.String(u.config.Bucket),
+		Key:                aws.String(u.config.Key),
+		ChecksumAlgorithm:  serverChecksumAlgorithm(u.config.ServerChecksumAlgorithm),
+		ChecksumType:       serverChecksumType(u.config.ServerChecksumType),
+		ContentType:        h.contentType,
+		ContentDisposition: h.contentDisposition,
+		ContentEncoding:    h.contentEncoding,
+		ContentLanguage:    h.contentLanguage,
+		CacheControl:       h.cacheControl,
+		Metadata:           h.metadata,
+		Tagging:            h.tagging,
+		StorageClass:       h.storageClass,
+		ACL:                h.acl,
+
+		ServerSideEncryption:    u.sse.sse,
+		SSEKMSKeyId:             u.sse.kmsKeyID,
+		SSEKMSEncryptionContext: u.sse.kmsContext,
+		SSECustomerAlgorithm:    u.sse.customerAlgorithm,
+		SSECustomerKey:          u.sse.customerKey,
+		SSECustomerKeyMD5:       u.sse.customerKeyMD5,
+	}
+
+	var resp *s3.CreateMultipartUploadOutput
+	err := u.withRequestRetry(u.ctx, u.retryPolicy, "CreateMultipartUpload", func(ctx context.Context) error {
+		var err error
+		resp, err = u.s3Client.CreateMultipartUpload(ctx, input)
+		return err
+	})
+	if err != nil {
+		return &UploadError{Operation: "CreateMultipartUpload", Err: err}
 	}
 
+	u.uploadID = *resp.UploadId
+	return nil
+}
+
+// objectHeaders holds the object-level settings shared by multipart and
+// single-request uploads.
+type objectHeaders struct {
+	contentType        *string
+	contentDisposition *string
+	contentEncoding    *string
+	contentLanguage    *string
+	cacheControl       *string
+	metadata           map[string]string
+	tagging            *string
+	storageClass       types.StorageClass
+	acl                types.ObjectCannedACL
+}
+
+// headers resolves the object headers from the configuration.
+func (u *Uploader) headers() objectHeaders {
 	// Set Content-Type (auto-detect if not provided)
 	contentType := u.config.ContentType
 	if contentType == "" {
 		contentType = DetectContentType(u.config.Key)
 	}
-	if contentType != "" {
-		input.ContentType = aws.String(contentType)
+
+	h := objectHeaders{
+		contentType:        optionalString(contentType),
+		contentDisposition: optionalString(u.config.ContentDisposition),
+		contentEncoding:    optionalString(u.config.ContentEncoding),
+		contentLanguage:    optionalString(u.config.ContentLanguage),
+		cacheControl:       optionalString(u.config.CacheControl),
+		storageClass:       types.StorageClass(u.config.StorageClass),
+		acl:                types.ObjectCannedACL(u.config.ACL),
+	}
+	if len(u.config.Tags) > 0 {
+		h.tagging = optionalString(encodeTags(u.config.Tags))
 	}
 
-	// Set optional HTTP headers
-	if u.config.ContentDisposition != "" {
-		input.ContentDisposition = aws.String(u.config.ContentDisposition)
+	// Set custom metadata, plus what is needed to decode the object
+	extra := u.envelopeMetadata()
+	if u.compression != "" && u.originalSize != UnknownSize {
+		if extra == nil {
+			extra = make(map[string]string, 1)
+		}
+		extra[metaOriginalSize] = strconv.FormatInt(u.originalSize, 10)
 	}
-	if u.config.ContentEncoding != "" {
-		input.ContentEncoding = aws.String(u.config.ContentEncoding)
+	if len(u.config.Metadata) > 0 {
+		h.metadata = u.config.Metadata
 	}
-	if u.config.ContentLanguage != "" {
-		input.ContentLanguage = aws.String(u.config.ContentLanguage)
+	if len(extra) > 0 {
+		h.metadata = make(map[string]string, len(u.config.Metadata)+len(extra))
+		for k, v := range u.config.Metadata {
+			h.metadata[k] = v
+		}
+		for k, v := range extra {
+			h.metadata[k] = v
+		}
 	}
-	if u.config.CacheControl != "" {
-		input.CacheControl = aws.String(u.config.CacheControl)
+
+	return h
+}
+
+// envelopeMetadata returns the encryption metadata, or nil when not encrypting.
+func (u *Uploader) envelopeMetadata() map[string]string {
+	if u.envelope == nil {
+		return nil
 	}
+	return u.envelope.metadata()
+}
 
-	// Set custom metadata
-	if len(u.config.Metadata) > 0 {
-		input.Metadata = u.config.Metadata
+// optionalString returns nil for empty strings so unset headers are omitted.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// putObject uploads a small object in a single request.
+func (u *Uploader) putObject(data []byte) error {
+	h := u.headers()
+	algorithm := u.config.ServerChecksumAlgorithm
+	sum := newChecksumFields(algorithm, computeServerChecksum(algorithm, data))
+
+	var contentMD5 *string
+	if u.config.SendContentMD5 {
+		contentMD5 = aws.String(computeContentMD5(data))
 	}
 
-	resp, err := u.s3Client.CreateMultipartUpload(u.ctx, input)
+	size := int64(len(data))
+	u.emit(PartStarted{Part: 1, Size: size})
+	started := time.Now()
+
+	// Attempts are limited and throttled like those of a part
+	var resp *s3.PutObjectOutput
+	err := u.withPartRetry("PutObject", 1, func() error {
+		if u.concurrency != nil {
+			if err := u.concurrency.acquire(u.ctx); err != nil {
+				return err
+			}
+		}
+		err := withTimeout(u.ctx, "PutObject", u.config.PartTimeout, func(ctx context.Context) error {
+			var err error
+			resp, err = u.s3Client.PutObject(ctx, &s3.PutObjectInput{
+				Bucket:             aws.String(u.config.Bucket),
+				Key:                aws.String(u.config.Key),
+				Body:               bytes.NewReader(data),
+				ContentLength:      aws.Int64(size),
+				ContentMD5:         contentMD5,
+				ChecksumAlgorithm:  serverChecksumAlgorithm(algorithm),
+				ChecksumCRC32:      sum.crc32,
+				ChecksumCRC32C:     sum.crc32c,
+				ChecksumCRC64NVME:  sum.crc64nvme,
+				ChecksumSHA1:       sum.sha1,
+				ChecksumSHA256:     sum.sha256,
+				ContentType:        h.contentType,
+				ContentDisposition: h.contentDisposition,
+				ContentEncoding:    h.contentEncoding,
+				ContentLanguage:    h.contentLanguage,
+				CacheControl:       h.cacheControl,
+				Metadata:           h.metadata,
+				Tagging:            h.tagging,
+				StorageClass:       h.storageClass,
+				ACL:                h.acl,
+
+				ServerSideEncryption:    u.sse.sse,
+				SSEKMSKeyId:             u.sse.kmsKeyID,
+				SSEKMSEncryptionContext: u.sse.kmsContext,
+				SSECustomerAlgorithm:    u.sse.customerAlgorithm,
+				SSECustomerKey:          u.sse.customerKey,
+				SSECustomerKeyMD5:       u.sse.customerKeyMD5,
+				IfNoneMatch:             u.ifNoneMatch(),
+				IfMatch:                 optionalString(u.config.IfMatch),
+			}, u.s3Options...)
+			return err
+		})
+		if u.concurrency != nil {
+			u.concurrency.release(isThrottleError(err))
+		}
+		return err
+	})
 	if err != nil {
-		return &UploadError{Operation: "CreateMultipartUpload", Err: err}
+		u.emit(PartFailed{Part: 1, Err: err, Duration: time.Since(started)})
+		if isPreconditionFailed(err) {
+			return &PreconditionFailedError{Key: u.config.Key, Err: err}
+		}
+		return &UploadError{Operation: "PutObject", Err: err}
+	}
+	u.etag = aws.ToString(resp.ETag)
+
+	if algorithm != "" {
+		u.setServerChecksum(checksumFields{
+			crc32:     resp.ChecksumCRC32,
+			crc32c:    resp.ChecksumCRC32C,
+			crc64nvme: resp.ChecksumCRC64NVME,
+			sha1:      resp.ChecksumSHA1,
+			sha256:    resp.ChecksumSHA256,
+		})
+	}
+
+	// Report the whole object as a single part
+	u.bytesUploaded.Add(size)
+	u.partsUploaded.Add(1)
+	if u.config.ProgressCallback != nil {
+		u.config.ProgressCallback(u.progressBytes(), u.partsUploaded.Load())
+	}
+	u.emit(PartCompleted{Part: 1, Size: size, ETag: u.etag, Duration: time.Since(started)})
+
+	return nil
+}
+
+// startResumableUpload continues the upload recorded in the resume journal, or
+// starts a new multipart upload and journals it if there is nothing to resume.
+func (u *Uploader) startResumableUpload() error {
+	path := u.config.ResumeJournal
+
+	existing, err := loadJournal(path)
+	if err != nil {
+		return &UploadError{Operation: "loading resume journal", Err: err}
+	}
+
+	var parts []journalPart
+	if existing != nil {
+		h := existing.header
+		encrypted := u.envelope != nil
+		if h.Bucket != u.config.Bucket || h.Key != u.config.Key || h.FileSize != u.config.FileSize ||
+			(h.Encryption != nil) != encrypted {
+			return &UploadError{
+				Operation: "loading resume journal",
+				Err: fmt.Errorf("journal %s belongs to a different upload (s3://%s/%s, %d bytes)",
+					path, h.Bucket, h.Key, h.FileSize),
+			}
+		}
+
+		var alive bool
+		parts, alive, err = u.reconcileParts(existing)
+		if err != nil {
+			return err
+		}
+
+		// Reuse the original upload and part sizes so the remaining parts line up
+		if alive {
+			u.uploadID = h.UploadID
+			u.schedule = existing.schedule()
+			u.partSize = u.schedule.InitialSize
+
+			// Re-encrypting with the original data key reproduces the uploaded parts
+			if u.envelope != nil {
+				if u.envelope, err = openEnvelope(u.config.Encryption, h.Encryption); err != nil {
+					return &UploadError{Operation: "loading resume journal", Err: err}
+				}
+			}
+		}
+	}
+
+	// Nothing to resume: start a new upload
+	if u.uploadID == "" {
+		if err := u.initializeMultipartUpload(); err != nil {
+			return err
+		}
+		parts = nil
+	}
+
+	u.journal, err = createJournal(path, journalHeader{
+		Bucket:          u.config.Bucket,
+		Key:             u.config.Key,
+		UploadID:        u.uploadID,
+		FileSize:        u.config.FileSize,
+		InitialPartSize: u.schedule.InitialSize,
+		MaxPartSize:     u.schedule.MaxSize,
+		GrowthInterval:  u.schedule.GrowthInterval,
+		Encryption:      u.envelopeMetadata(),
+	}, parts)
+	if err != nil {
+		return &UploadError{Operation: "writing resume journal", Err: err}
+	}
+
+	// Count already uploaded parts towards progress
+	u.resumed = make(map[int32]journalPart, len(parts))
+	for _, p := range parts {
+		u.resumed[p.Number] = p
+		u.bytesUploaded.Add(p.Size)
+		u.partsUploaded.Add(1)
 	}
 
-	u.uploadID = *resp.UploadId
 	return nil
 }
 
+// reconcileParts compares the journal with the parts the server actually has.
+// It returns the journaled parts that the server confirms, and whether the
+// upload still exists at all.
+func (u *Uploader) reconcileParts(j *resumeJournal) ([]journalPart, bool, error) {
+	onServer := make(map[int32]types.Part)
+
+	paginator := s3.NewListPartsPaginator(u.s3Client, &s3.ListPartsInput{
+		Bucket:               aws.String(u.config.Bucket),
+		Key:                  aws.String(u.config.Key),
+		UploadId:             aws.String(j.header.UploadID),
+		SSECustomerAlgorithm: u.sse.customerAlgorithm,
+		SSECustomerKey:       u.sse.customerKey,
+		SSECustomerKeyMD5:    u.sse.customerKeyMD5,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(u.ctx)
+		if err != nil {
+			if isNoSuchUpload(err) {
+				// Upload was aborted or expired; start over
+				return nil, false, nil
+			}
+			return nil, false, &UploadError{Operation: "ListParts", Err: err}
+		}
+		for _, p := range page.Parts {
+			if p.PartNumber != nil {
+				onServer[*p.PartNumber] = p
+			}
+		}
+	}
+
+	var confirmed []journalPart
+	for _, p := range j.parts {
+		sp, ok := onServer[p.Number]
+		if !ok || sp.ETag == nil || sp.Size == nil {
+			continue
+		}
+		if normalizeETag(*sp.ETag) != normalizeETag(p.ETag) || *sp.Size != p.Size {
+			continue
+		}
+		confirmed = append(confirmed, p)
+	}
+
+	return confirmed, true, nil
+}
+
+// normalizeETag strips the surrounding quotes some services include in ETags.
+func normalizeETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// skipResumedParts advances a seekable reader past the leading run of parts
+// that were already uploaded, returning the next part number to read.
+// Sources that are not seekable, or uploads that need a checksum of the whole
+// stream, are read through instead (see produceparts).
+func (u *Uploader) skipResumedParts(reader io.Reader) (int32, error) {
+	var partNumber int32 = 1
+
+	seeker, ok := reader.(io.Seeker)
+	if !ok || u.checksumHash != nil || u.objectChecksumHash != nil || len(u.resumed) == 0 {
+		return partNumber, nil
+	}
+
+	var offset int64
+	for {
+		if _, done := u.resumed[partNumber]; !done {
+			break
+		}
+		offset += u.schedule.PartSize(partNumber)
+		partNumber++
+	}
+
+	if _, err := seeker.Seek(offset, io.SeekCurrent); err != nil {
+		return 0, &UploadError{Operation: "skipping uploaded parts", Err: err}
+	}
+
+	return partNumber, nil
+}
+
 // produceParts reads data from the reader and sends parts to the workers.
+// Each part is read straight into a pooled buffer which the worker hands back
+// once the part is uploaded, so reading stalls when all buffers are in use.
 func (u *Uploader) produceparts(reader io.Reader, partsChan chan<- part) error {
-	buffer := make([]byte, u.partSize)
-	var partNumber int32 = 1
+	// Skip ahead over parts uploaded by a previous attempt
+	partNumber, err := u.skipResumedParts(reader)
+	if err != nil {
+		return err
+	}
 
 	for {
 		// Check for cancellation
@@ -269,29 +947,73 @@ func (u *Uploader) produceparts(reader io.Reader, partsChan chan<- part) error {
 		default:
 		}
 
+		// Wait for a free buffer sized for this part
+		buffer, err := u.pool.get(u.ctx, u.schedule.PartSize(partNumber))
+		if err != nil {
+			return err
+		}
+
 		// Read a chunk
 		n, err := io.ReadFull(reader, buffer)
 		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			u.pool.put(buffer)
 			return &UploadError{Operation: "reading data", Err: err}
 		}
 
+		// An empty stream is uploaded as a single empty part, since a
+		// multipart upload can't be completed without any
+		empty := n == 0 && partNumber == 1
+
+		// Nothing left to read; the buffer is not needed
+		if n == 0 && !empty {
+			u.pool.put(buffer)
+		}
+
 		// If we read something, send it
-		if n > 0 {
+		if n > 0 || empty {
+			// Refuse to go past the service part limit
+			if int(partNumber) > u.config.ServiceLimits.MaxParts {
+				u.pool.put(buffer)
+				return &UploadError{
+					Operation: "reading data",
+					Err:       fmt.Errorf("stream exceeds the maximum of %d parts", u.config.ServiceLimits.MaxParts),
+				}
+			}
+
 			// Hash the data if checksum is enabled
-			if u.checksumHash != nil {
+			if u.checksumHash != nil || u.objectChecksumHash != nil {
 				u.checksumMu.Lock()
-				u.checksumHash.Write(buffer[:n])
+				if u.checksumHash != nil {
+					u.checksumHash.Write(buffer[:n])
+				}
+				if u.objectChecksumHash != nil {
+					u.objectChecksumHash.Write(buffer[:n])
+				}
 				u.checksumMu.Unlock()
 			}
 
-			// Create a copy of the data for this part
-			partData := make([]byte, n)
-			copy(partData, buffer[:n])
+			// Already uploaded by a previous attempt: just check it still lines up
+			if prev, done := u.resumed[partNumber]; done {
+				u.pool.put(buffer)
+				if prev.Size != int64(n) {
+					return &UploadError{
+						Operation: "resuming upload",
+						Err: fmt.Errorf("part %d is %d bytes but was %d bytes in the previous attempt (has the source changed?)",
+							partNumber, n, prev.Size),
+					}
+				}
+				partNumber++
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					break
+				}
+				continue
+			}
 
 			select {
-			case partsChan <- part{number: partNumber, data: partData}:
+			case partsChan <- part{number: partNumber, data: buffer[:n]}:
 				partNumber++
 			case <-u.ctx.Done():
+				u.pool.put(buffer)
 				return u.ctx.Err()
 			}
 		}
@@ -323,6 +1045,17 @@ func isRetryableError(err error) bool {
 		return true
 	}
 
+	// A failed precondition will not change on retry
+	if isPreconditionFailed(err) {
+		return false
+	}
+
+	// An attempt that ran out of time may succeed on the next try
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return true
+	}
+
 	// AWS API errors
 	var apiErr smithy.APIError
 	if errors.As(err, &apiErr) {
@@ -331,10 +1064,18 @@ func isRetryableError(err error) bool {
 		case "InternalError", "ServiceUnavailable", "SlowDown", "RequestTimeout":
 			return true
 		}
-		// Check HTTP status code if available
-		code := apiErr.ErrorCode()
-		if len(code) >= 3 && code[0] == '5' {
+	}
+
+	// Otherwise go by the HTTP status: server errors, throttling and request
+	// timeouts are retried, other client errors will fail again
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		status := respErr.HTTPStatusCode()
+		switch {
+		case status >= 500, status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
 			return true
+		case status >= 400:
+			return false
 		}
 	}
 
@@ -347,17 +1088,83 @@ func isRetryableError(err error) bool {
 	return true
 }
 
-// calculateBackoff calculates the backoff duration for a retry attempt using exponential backoff.
-func (u *Uploader) calculateBackoff(attempt int) time.Duration {
-	// Calculate exponential backoff: initialDelay * (multiplier ^ attempt)
-	backoffMs := float64(u.config.RetryDelay) * math.Pow(float64(u.config.RetryMultiplier), float64(attempt))
-
-	// Cap at max delay
-	if backoffMs > float64(u.config.MaxRetryDelay) {
-		backoffMs = float64(u.config.MaxRetryDelay)
+// defaultRetryPolicy builds the DefaultRetryPolicy for the upload's settings.
+func (u *Uploader) defaultRetryPolicy() *DefaultRetryPolicy {
+	return &DefaultRetryPolicy{
+		MaxRetries: u.config.MaxRetries,
+		BaseDelay:  time.Duration(u.config.RetryDelay) * time.Millisecond,
+		MaxDelay:   time.Duration(u.config.MaxRetryDelay) * time.Millisecond,
+		Multiplier: float64(u.config.RetryMultiplier),
+		Budget:     u.config.RetryBudget,
 	}
+}
+
+// abortRetryPolicy is the default retry policy without the retry budget, so
+// that an upload which ran out of retries can still be aborted.
+func (u *Uploader) abortRetryPolicy() *DefaultRetryPolicy {
+	policy := u.defaultRetryPolicy()
+	policy.Budget = 0
+	return policy
+}
+
+// withRetry calls fn until it succeeds, the retry policy gives up or the
+// upload is cancelled. operation names the request in logs.
+func (u *Uploader) withRetry(operation string, fn func() error) error {
+	return u.retry(u.ctx, u.retryPolicy, operation, 0, fn)
+}
+
+// withPartRetry is withRetry for uploading a part, reporting each retry of
+// the part to the observer.
+func (u *Uploader) withPartRetry(operation string, partNumber int32, fn func() error) error {
+	return u.retry(u.ctx, u.retryPolicy, operation, partNumber, fn)
+}
 
-	return time.Duration(backoffMs) * time.Millisecond
+// withRequestRetry retries a request that manages the upload (create,
+// complete, abort) with policy, giving each attempt Config.RequestTimeout
+// until ctx is done.
+func (u *Uploader) withRequestRetry(ctx context.Context, policy RetryPolicy, operation string, fn func(ctx context.Context) error) error {
+	return u.retry(ctx, policy, operation, 0, func() error {
+		return withTimeout(ctx, operation, u.config.RequestTimeout, fn)
+	})
+}
+
+// retry calls fn until it succeeds, policy gives up or ctx is done.
+func (u *Uploader) retry(ctx context.Context, policy RetryPolicy, operation string, partNumber int32, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		// Check for cancellation before each attempt
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		err := fn()
+
+		// Success!
+		if err == nil {
+			return nil
+		}
+
+		// Let the policy decide whether (and when) to try again
+		backoff, giveUp := policy.Retry(attempt, err)
+		if giveUp != nil {
+			return giveUp
+		}
+		if partNumber > 0 {
+			u.emit(PartRetry{Part: partNumber, Operation: operation, Attempt: attempt, Err: err, Backoff: backoff})
+		} else {
+			u.logger.Warn("retrying request", append([]any{"operation", operation, "attempt", attempt,
+				"backoff", backoff}, errorAttrs(err)...)...)
+		}
+
+		// Sleep with context awareness
+		select {
+		case <-time.After(backoff):
+			// Continue to next retry
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // uploadWorker uploads parts from the channel with retry logic.
@@ -368,105 +1175,168 @@ func (u *Uploader) uploadWorker(wg *sync.WaitGroup, partsChan <-chan part, resul
 		// Check for cancellation
 		select {
 		case <-u.ctx.Done():
+			u.releasePart(p)
 			resultsChan <- completedPart{number: p.number, err: u.ctx.Err()}
 			continue
 		default:
 		}
 
-		// Upload the part with retry logic
-		var resp *s3.UploadPartOutput
-		var err error
+		size := p.size()
+		u.emit(PartStarted{Part: p.number, Size: size})
+		started := time.Now()
 
-	retryLoop:
-		for attempt := 0; attempt <= u.config.MaxRetries; attempt++ {
-			// Check for cancellation before each attempt
-			select {
-			case <-u.ctx.Done():
-				resultsChan <- completedPart{number: p.number, err: u.ctx.Err()}
-				goto nextPart
-			default:
-			}
-
-			// Attempt upload
-			resp, err = u.s3Client.UploadPart(u.ctx, &s3.UploadPartInput{
-				Bucket:     aws.String(u.config.Bucket),
-				Key:        aws.String(u.config.Key),
-				UploadId:   aws.String(u.uploadID),
-				PartNumber: aws.Int32(p.number),
-				Body:       bytes.NewReader(p.data),
-			})
+		// Per-part integrity checks, verified by the service
+		sums, err := u.partSums(p)
+		if err != nil {
+			u.releasePart(p)
+			resultsChan <- completedPart{number: p.number, err: err}
+			u.emit(PartFailed{Part: p.number, Err: err, Duration: time.Since(started)})
+			continue
+		}
 
-			// Success!
-			if err == nil {
-				break
+		// Upload the part with retry logic (sections are read again on each
+		// attempt). A slow attempt is given up after PartTimeout, or hedged.
+		var resp *s3.UploadPartOutput
+		err = u.withPartRetry("UploadPart", p.number, func() error {
+			// Wait for a slot when adapting to throttling
+			if u.concurrency != nil {
+				if err := u.concurrency.acquire(u.ctx); err != nil {
+					return err
+				}
 			}
 
-			// Check if error is retryable
-			if !isRetryableError(err) {
-				// Non-retryable error, fail immediately
-				break
-			}
+			err := withTimeout(u.ctx, "UploadPart", u.config.PartTimeout, func(ctx context.Context) error {
+				var err error
+				resp, err = u.sendPart(ctx, p, func(ctx context.Context) (*s3.UploadPartOutput, error) {
+					return u.s3Client.UploadPart(ctx, u.uploadPartInput(p, sums), u.s3Options...)
+				})
+				return err
+			})
 
-			// Last attempt failed, don't sleep
-			if attempt == u.config.MaxRetries {
-				break
+			if u.concurrency != nil {
+				u.concurrency.release(isThrottleError(err))
 			}
+			return err
+		})
 
-			// Calculate backoff and sleep
-			backoff := u.calculateBackoff(attempt)
-
-			// Sleep with context awareness
-			select {
-			case <-time.After(backoff):
-				// Continue to next retry
-			case <-u.ctx.Done():
-				err = u.ctx.Err()
-				break retryLoop
-			}
-		}
+		// The part body is no longer needed; hand the buffer back to the producer
+		u.releasePart(p)
 
 		// Check final result
 		if err != nil {
 			resultsChan <- completedPart{number: p.number, err: err}
+			u.emit(PartFailed{Part: p.number, Err: err, Duration: time.Since(started)})
 			continue
 		}
 
 		// Send successful result
 		resultsChan <- completedPart{
-			number: p.number,
-			etag:   *resp.ETag,
-			err:    nil,
+			number:   p.number,
+			etag:     *resp.ETag,
+			size:     size,
+			checksum: sums.checksum,
+			digest:   sums.digest,
+			err:      nil,
 		}
 
 		// Update progress
-		u.bytesUploaded.Add(int64(len(p.data)))
+		u.bytesUploaded.Add(size)
 		u.partsUploaded.Add(1)
 
 		// Call progress callback if provided
 		if u.config.ProgressCallback != nil {
-			u.config.ProgressCallback(u.bytesUploaded.Load(), u.partsUploaded.Load())
+			u.config.ProgressCallback(u.progressBytes(), u.partsUploaded.Load())
 		}
+		u.emit(PartCompleted{Part: p.number, Size: size, ETag: *resp.ETag, Duration: time.Since(started)})
+	}
+}
 
-	nextPart:
+// uploadPartInput builds the UploadPart request for a part, with a fresh body.
+func (u *Uploader) uploadPartInput(p part, sums partSums) *s3.UploadPartInput {
+	algorithm := u.config.ServerChecksumAlgorithm
+	sum := newChecksumFields(algorithm, sums.checksum)
+	return &s3.UploadPartInput{
+		Bucket:            aws.String(u.config.Bucket),
+		Key:               aws.String(u.config.Key),
+		UploadId:          aws.String(u.uploadID),
+		PartNumber:        aws.Int32(p.number),
+		Body:              p.body(),
+		ContentLength:     aws.Int64(p.size()),
+		ContentMD5:        sums.contentMD5,
+		ChecksumAlgorithm: serverChecksumAlgorithm(algorithm),
+		ChecksumCRC32:     sum.crc32,
+		ChecksumCRC32C:    sum.crc32c,
+		ChecksumCRC64NVME: sum.crc64nvme,
+		ChecksumSHA1:      sum.sha1,
+		ChecksumSHA256:    sum.sha256,
+
+		// SSE-C requires the key on every part
+		SSECustomerAlgorithm: u.sse.customerAlgorithm,
+		SSECustomerKey:       u.sse.customerKey,
+		SSECustomerKeyMD5:    u.sse.customerKeyMD5,
+	}
+}
+
+// releasePart hands a buffered part's memory back to the producer.
+func (u *Uploader) releasePart(p part) {
+	if p.data != nil {
+		u.pool.put(p.data)
 	}
 }
 
 // collectResults gathers ETags from completed uploads.
+//
+// On the first failure it cancels the upload so the producer stops reading,
+// and keeps draining results so that no worker blocks on a full channel.
 func (u *Uploader) collectResults(resultsChan <-chan completedPart) ([]types.CompletedPart, error) {
 	var parts []types.CompletedPart
+	var firstErr error
+
+	// Include parts uploaded by a previous attempt; their data was not hashed
+	// this time, so fall back to the part ETag for verification
+	u.partDigests = make(map[int32][]byte)
+	for _, p := range u.resumed {
+		parts = append(parts, u.completedPartInput(p.Number, p.ETag, p.Checksum))
+		u.bytesProduced += p.Size
+		u.partDigests[p.Number] = etagDigest(p.ETag)
+	}
 
 	for result := range resultsChan {
+		if firstErr != nil {
+			continue
+		}
+
 		if result.err != nil {
-			return nil, &UploadError{
+			firstErr = &UploadError{
 				Operation: fmt.Sprintf("uploading part %d", result.number),
 				Err:       result.err,
 			}
+			u.cancel()
+			continue
 		}
 
-		parts = append(parts, types.CompletedPart{
-			PartNumber: aws.Int32(result.number),
-			ETag:       aws.String(result.etag),
-		})
+		// Record the part so a later attempt can skip it
+		if u.journal != nil {
+			err := u.journal.append(journalPart{
+				Number:   result.number,
+				ETag:     result.etag,
+				Size:     result.size,
+				Checksum: result.checksum,
+			})
+			if err != nil {
+				firstErr = &UploadError{Operation: "writing resume journal", Err: err}
+				u.cancel()
+				continue
+			}
+		}
+
+		parts = append(parts, u.completedPartInput(result.number, result.etag, result.checksum))
+		u.bytesProduced += result.size
+		u.partDigests[result.number] = result.digest
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
 	}
 
 	// Sort parts by number (required by S3)
@@ -477,24 +1347,168 @@ func (u *Uploader) collectResults(resultsChan <-chan completedPart) ([]types.Com
 	return parts, nil
 }
 
+// completedPartInput builds the CompleteMultipartUpload entry for a part.
+func (u *Uploader) completedPartInput(number int32, etag, checksum string) types.CompletedPart {
+	sum := newChecksumFields(u.config.ServerChecksumAlgorithm, checksum)
+	return types.CompletedPart{
+		PartNumber:        aws.Int32(number),
+		ETag:              aws.String(etag),
+		ChecksumCRC32:     sum.crc32,
+		ChecksumCRC32C:    sum.crc32c,
+		ChecksumCRC64NVME: sum.crc64nvme,
+		ChecksumSHA1:      sum.sha1,
+		ChecksumSHA256:    sum.sha256,
+	}
+}
+
 // completeMultipartUpload finalizes the upload.
 func (u *Uploader) completeMultipartUpload(parts []types.CompletedPart) error {
-	_, err := u.s3Client.CompleteMultipartUpload(u.ctx, &s3.CompleteMultipartUploadInput{
+	input := &s3.CompleteMultipartUploadInput{
 		Bucket:   aws.String(u.config.Bucket),
 		Key:      aws.String(u.config.Key),
 		UploadId: aws.String(u.uploadID),
 		MultipartUpload: &types.CompletedMultipartUpload{
 			Parts: parts,
 		},
-	})
+		SSECustomerAlgorithm: u.sse.customerAlgorithm,
+		SSECustomerKey:       u.sse.customerKey,
+		SSECustomerKeyMD5:    u.sse.customerKeyMD5,
+		IfNoneMatch:          u.ifNoneMatch(),
+		IfMatch:              optionalString(u.config.IfMatch),
+	}
+
+	// Let the service verify the whole-object checksum we calculated while reading
+	if u.config.ServerChecksumAlgorithm != "" {
+		input.ChecksumType = serverChecksumType(u.config.ServerChecksumType)
+	}
+	if u.objectChecksumHash != nil {
+		u.checksumMu.Lock()
+		value := base64.StdEncoding.EncodeToString(u.objectChecksumHash.Sum(nil))
+		u.checksumMu.Unlock()
+
+		sum := newChecksumFields(u.config.ServerChecksumAlgorithm, value)
+		input.ChecksumCRC32 = sum.crc32
+		input.ChecksumCRC32C = sum.crc32c
+		input.ChecksumCRC64NVME = sum.crc64nvme
+	}
+
+	// S3 may fail a completion after it has sent 200 OK; the SDK turns the
+	// error in the body into an error, and an empty result is one too
+	var resp *s3.CompleteMultipartUploadOutput
+	attempt := 0
+	err := u.withRequestRetry(u.ctx, u.retryPolicy, "CompleteMultipartUpload", func(ctx context.Context) error {
+		attempt++
+		var err error
+		resp, err = u.s3Client.CompleteMultipartUpload(ctx, input)
+		if err == nil && resp.ETag == nil {
+			err = errors.New("response has no ETag (incomplete 200 OK response)")
+		}
 
+		// A retry finds the upload gone (or, with IfNoneMatch, the object
+		// there) when an earlier attempt completed but its response was lost
+		if attempt > 1 && (isNoSuchUpload(err) || isPreconditionFailed(err)) {
+			completed, headErr := u.completedEarlier(ctx)
+			if headErr != nil && isRetryableError(headErr) {
+				return headErr
+			}
+			if completed != nil {
+				resp = completed
+				return nil
+			}
+		}
+		return err
+	})
 	if err != nil {
+		if isPreconditionFailed(err) {
+			return &PreconditionFailedError{Key: u.config.Key, Err: err}
+		}
 		return &UploadError{Operation: "CompleteMultipartUpload", Err: err}
 	}
+	u.etag = aws.ToString(resp.ETag)
+
+	if u.config.ServerChecksumAlgorithm != "" {
+		u.setServerChecksum(checksumFields{
+			crc32:     resp.ChecksumCRC32,
+			crc32c:    resp.ChecksumCRC32C,
+			crc64nvme: resp.ChecksumCRC64NVME,
+			sha1:      resp.ChecksumSHA1,
+			sha256:    resp.ChecksumSHA256,
+		})
+	}
+
+	return nil
+}
+
+// completedEarlier checks whether the object now stored is the one this upload
+// completes, and if so returns its details as a completion result. Only an
+// exact match of the locally computed multipart ETag counts, so with SSE-KMS
+// or SSE-C, whose ETags aren't MD5 digests, it never finds one.
+func (u *Uploader) completedEarlier(ctx context.Context) (*s3.CompleteMultipartUploadOutput, error) {
+	expected := ""
+	if u.sse.etagIsMD5() {
+		expected = multipartETag(u.partDigests)
+	}
+	if expected == "" {
+		return nil, nil
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket:               aws.String(u.config.Bucket),
+		Key:                  aws.String(u.config.Key),
+		SSECustomerAlgorithm: u.sse.customerAlgorithm,
+		SSECustomerKey:       u.sse.customerKey,
+		SSECustomerKeyMD5:    u.sse.customerKeyMD5,
+	}
+	if u.config.ServerChecksumAlgorithm != "" {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+	head, err := u.s3Client.HeadObject(ctx, input)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	etag := normalizeETag(aws.ToString(head.ETag))
+	if etag != expected || aws.ToInt64(head.ContentLength) != u.bytesProduced {
+		return nil, nil
+	}
+
+	u.logger.Info("an earlier CompleteMultipartUpload attempt succeeded", "upload_id", u.uploadID, "etag", etag)
+	return &s3.CompleteMultipartUploadOutput{
+		ETag:              head.ETag,
+		ChecksumCRC32:     head.ChecksumCRC32,
+		ChecksumCRC32C:    head.ChecksumCRC32C,
+		ChecksumCRC64NVME: head.ChecksumCRC64NVME,
+		ChecksumSHA1:      head.ChecksumSHA1,
+		ChecksumSHA256:    head.ChecksumSHA256,
+	}, nil
+}
 
+// ifNoneMatch returns the If-None-Match value for create-only uploads.
+func (u *Uploader) ifNoneMatch() *string {
+	if u.config.IfNoneMatch {
+		return aws.String("*")
+	}
 	return nil
 }
 
+// emit logs an event and sends it to the observer, if any.
+func (u *Uploader) emit(event Event) {
+	logEvent(u.logger, event)
+	if u.config.Observer != nil {
+		u.config.Observer.OnEvent(event)
+	}
+}
+
+// setServerChecksum records the object checksum reported by the service.
+func (u *Uploader) setServerChecksum(sum checksumFields) {
+	u.checksumMu.Lock()
+	defer u.checksumMu.Unlock()
+	u.serverChecksum = sum.value()
+}
+
 // Abort cancels the upload and cleans up any uploaded parts.
 func (u *Uploader) Abort() error {
 	u.cancel()
@@ -503,12 +1517,33 @@ func (u *Uploader) Abort() error {
 		return nil // Nothing to abort
 	}
 
-	_, err := u.s3Client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
-		Bucket:   aws.String(u.config.Bucket),
-		Key:      aws.String(u.config.Key),
-		UploadId: aws.String(u.uploadID),
+	// The upload's context is cancelled by now, so aborting gets its own,
+	// bounded so that an interrupted upload still exits promptly
+	ctx, cancel := context.WithTimeout(context.Background(), u.config.AbortTimeout)
+	defer cancel()
+
+	// The failures that led here may have used up the retry budget
+	policy := u.retryPolicy
+	if u.config.RetryPolicy == nil {
+		policy = u.abortRetryPolicy()
+	}
+
+	err := u.withRequestRetry(ctx, policy, "AbortMultipartUpload", func(ctx context.Context) error {
+		_, err := u.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(u.config.Bucket),
+			Key:      aws.String(u.config.Key),
+			UploadId: aws.String(u.uploadID),
+		})
+		// Already gone, e.g. a retry after an abort whose response was lost
+		if isNoSuchUpload(err) {
+			return nil
+		}
+		return err
 	})
 
+	if err != nil && ctx.Err() != nil {
+		err = &TimeoutError{Operation: "AbortMultipartUpload", Timeout: u.config.AbortTimeout}
+	}
 	if err != nil {
 		return &UploadError{Operation: "AbortMultipartUpload", Err: err}
 	}
@@ -518,7 +1553,23 @@ func (u *Uploader) Abort() error {
 
 // GetProgress returns the current upload progress.
 func (u *Uploader) GetProgress() (bytesUploaded int64, partsUploaded int32) {
-	return u.bytesUploaded.Load(), u.partsUploaded.Load()
+	return u.progressBytes(), u.partsUploaded.Load()
+}
+
+// progressBytes returns bytes uploaded, counted in plaintext when encrypting.
+// With compression the stored size has no fixed relation to the source, so
+// progress counts source bytes consumed instead.
+func (u *Uploader) progressBytes() int64 {
+	return u.sourceSize(u.bytesUploaded.Load())
+}
+
+// GetConcurrency returns the number of parts currently allowed in flight.
+// This is Config.Workers unless adaptive concurrency has backed off.
+func (u *Uploader) GetConcurrency() int {
+	if u.concurrency != nil {
+		return u.concurrency.level()
+	}
+	return u.config.Workers
 }
 
 // GetChecksum returns the calculated checksum of the uploaded data.
@@ -528,3 +1579,12 @@ func (u *Uploader) GetChecksum() string {
 	defer u.checksumMu.Unlock()
 	return u.checksum
 }
+
+// GetServerChecksum returns the object checksum reported by the service
+// (base64, with a "-N" suffix for composite checksums).
+// Returns empty string if server-side checksums were not enabled or upload not completed.
+func (u *Uploader) GetServerChecksum() string {
+	u.checksumMu.Lock()
+	defer u.checksumMu.Unlock()
+	return u.serverChecksum
+}
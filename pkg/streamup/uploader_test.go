@@ -17,11 +17,17 @@ package streamup
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
 )
 
 func TestNew(t *testing.T) {
@@ -391,6 +397,61 @@ func TestProduceParts_SmallData(t *testing.T) {
 	}
 }
 
+func TestProduceParts_UnknownSizeGrowth(t *testing.T) {
+	cfg := Config{
+		AccessKeyID:     "test-access-key",
+		SecretAccessKey: "test-secret-key",
+		Bucket:          "test-bucket",
+		Key:             "test-key",
+		FileSize:        UnknownSize,
+	}
+
+	uploader, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() unexpected error = %v", err)
+	}
+
+	// Use a fast-growing schedule so the test stays small
+	const mb = 1024 * 1024
+	uploader.schedule = PartSizeSchedule{
+		InitialSize:    5 * mb,
+		MaxSize:        20 * mb,
+		GrowthInterval: 1,
+	}
+
+	testData := bytes.Repeat([]byte("x"), 18*mb)
+	partsChan := make(chan part, 10)
+
+	go func() {
+		if err := uploader.produceparts(bytes.NewReader(testData), partsChan); err != nil {
+			t.Errorf("produceParts() unexpected error = %v", err)
+		}
+		close(partsChan)
+	}()
+
+	var sizes []int
+	var reconstructed bytes.Buffer
+	for p := range partsChan {
+		sizes = append(sizes, len(p.data))
+		reconstructed.Write(p.data)
+	}
+
+	// 5 MB, then 10 MB, then the remaining 3 MB
+	wantSizes := []int{5 * mb, 10 * mb, 3 * mb}
+	if len(sizes) != len(wantSizes) {
+		t.Fatalf("produceParts() produced %d parts, want %d", len(sizes), len(wantSizes))
+	}
+	for i := range wantSizes {
+		if sizes[i] != wantSizes[i] {
+			t.Errorf("part %d size = %d, want %d", i+1, sizes[i], wantSizes[i])
+		}
+	}
+
+	if !bytes.Equal(reconstructed.Bytes(), testData) {
+		t.Error("Reconstructed data does not match original")
+	}
+}
+
 func TestProduceParts_Cancellation(t *testing.T) {
 	cfg := Config{
 		AccessKeyID:     "test-access-key",
@@ -476,10 +537,502 @@ func TestPartSorting(t *testing.T) {
 	}
 }
 
-// Note: Full integration tests that actually upload to S3/R2 would require:
-// 1. Mock S3 service (using something like localstack or minio)
-// 2. Real S3/R2 credentials (for end-to-end testing)
-// 3. Network connectivity
-//
-// These tests focus on the logic that can be tested without external dependencies.
-// For production use, consider adding integration tests in a separate test suite.
+// newFakeUploader creates an uploader backed by an in-memory fake S3 (or a
+// wrapper around one). Part sizes are the S3 minimum and retries are fast.
+func newFakeUploader(t *testing.T, api S3API, cfg Config) *Uploader {
+	t.Helper()
+
+	client, err := NewClient(ClientConfig{API: api})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	cfg.Bucket = "test-bucket"
+	cfg.Key = "test-key"
+	cfg.RetryDelay = 1
+	cfg.MaxRetryDelay = 5
+	uploader, err := client.NewUploader(cfg)
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	return uploader
+}
+
+// testData returns n bytes of non-repeating data.
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7 / 3)
+	}
+	return data
+}
+
+func TestUpload_Fake(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.MinPartSize = defaultMinPartSize
+	data := testData(12 * 1024 * 1024)
+
+	uploader := newFakeUploader(t, fake, Config{
+		FileSize:                int64(len(data)),
+		CalculateChecksum:       true,
+		ServerChecksumAlgorithm: ServerChecksumCRC32C,
+		VerifyUpload:            true,
+	})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	obj, ok := fake.Object("test-bucket", "test-key")
+	if !ok {
+		t.Fatal("object was not stored")
+	}
+	if !bytes.Equal(obj.Data, data) {
+		t.Error("stored data does not match the source")
+	}
+	if obj.Parts != 3 {
+		t.Errorf("Parts = %d, want 3", obj.Parts)
+	}
+	if uploader.GetServerChecksum() != obj.Checksum {
+		t.Errorf("GetServerChecksum() = %q, want %q", uploader.GetServerChecksum(), obj.Checksum)
+	}
+	if v := uploader.GetVerification(); v == nil || !v.ETagMatches() || !v.SizeMatches() {
+		t.Errorf("GetVerification() = %+v, want matching size and ETag", v)
+	}
+}
+
+func TestUpload_FakeSinglePart(t *testing.T) {
+	fake := streamuptest.NewFake()
+	data := testData(1024)
+
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if fake.Calls(streamuptest.OpPutObject) != 1 || fake.Calls(streamuptest.OpCreateMultipartUpload) != 0 {
+		t.Error("small objects should be written with a single PutObject")
+	}
+	if obj, ok := fake.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+		t.Error("stored data does not match the source")
+	}
+}
+
+func TestUpload_FakeSinglePartThreshold(t *testing.T) {
+	const threshold = 1024
+	tests := []struct {
+		name      string
+		size      int
+		putObject bool
+	}{
+		{"at threshold", threshold, true},
+		{"above threshold", threshold + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			data := testData(tt.size)
+
+			uploader := newFakeUploader(t, fake, Config{FileSize: UnknownSize, SinglePartThreshold: threshold})
+			if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			if got := fake.Calls(streamuptest.OpPutObject) == 1; got != tt.putObject {
+				t.Errorf("used PutObject = %v, want %v", got, tt.putObject)
+			}
+			if obj, ok := fake.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+				t.Error("stored data does not match the source")
+			}
+		})
+	}
+}
+
+func TestUpload_FakeFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func(f *streamuptest.Fake)
+		calls  int // Expected UploadPart calls for 3 parts
+	}{
+		{"throttled part", func(f *streamuptest.Fake) { f.ThrottlePart(2, 2) }, 5},
+		{"dropped connection", func(f *streamuptest.Fake) { f.DropConnection(3, 1) }, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			fake.MinPartSize = defaultMinPartSize
+			tt.inject(fake)
+			data := testData(12 * 1024 * 1024)
+
+			uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+			if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			if got := fake.Calls(streamuptest.OpUploadPart); got != tt.calls {
+				t.Errorf("UploadPart calls = %d, want %d", got, tt.calls)
+			}
+			if obj, ok := fake.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+				t.Error("stored data does not match the source")
+			}
+		})
+	}
+}
+
+func TestUpload_FakeCompleteFailureAborts(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	data := testData(6 * 1024 * 1024)
+
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+	if err := uploader.Upload(bytes.NewReader(data)); err == nil {
+		t.Fatal("Upload() should fail when CompleteMultipartUpload fails")
+	}
+
+	if _, ok := fake.Object("test-bucket", "test-key"); ok {
+		t.Error("object should not exist")
+	}
+	if ids := fake.Uploads(); len(ids) != 0 {
+		t.Errorf("uploads %v were not aborted", ids)
+	}
+	if fake.Calls(streamuptest.OpAbortMultipartUpload) != 1 {
+		t.Error("AbortMultipartUpload was not called")
+	}
+}
+
+func TestUpload_FakeControlRetries(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func(f *streamuptest.Fake)
+		op     string
+		calls  int
+	}{
+		{"create", func(f *streamuptest.Fake) {
+			f.AddFault(streamuptest.Fault{Operation: streamuptest.OpCreateMultipartUpload, Times: 1, Err: streamuptest.ErrInternal()})
+		}, streamuptest.OpCreateMultipartUpload, 2},
+		{"complete", func(f *streamuptest.Fake) { f.FailComplete(2) }, streamuptest.OpCompleteMultipartUpload, 3},
+		{"complete 200 OK with error body", func(f *streamuptest.Fake) {
+			f.AddFault(streamuptest.Fault{
+				Operation: streamuptest.OpCompleteMultipartUpload,
+				Times:     1,
+				Err:       streamuptest.APIError(http.StatusOK, "InternalError", "We encountered an internal error."),
+			})
+		}, streamuptest.OpCompleteMultipartUpload, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			tt.inject(fake)
+			data := testData(6 * 1024 * 1024)
+
+			uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+			if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+			if got := fake.Calls(tt.op); got != tt.calls {
+				t.Errorf("%s calls = %d, want %d", tt.op, got, tt.calls)
+			}
+			if obj, ok := fake.Object("test-bucket", "test-key"); !ok || !bytes.Equal(obj.Data, data) {
+				t.Error("stored data does not match the source")
+			}
+		})
+	}
+}
+
+// stallingAPI is a fake S3 whose first CreateMultipartUpload never answers,
+// and whose CompleteMultipartUpload succeeds without a result once.
+type stallingAPI struct {
+	*streamuptest.Fake
+	creates   atomic.Int32
+	completes atomic.Int32
+}
+
+func (s *stallingAPI) CreateMultipartUpload(ctx context.Context, in *s3.CreateMultipartUploadInput, opts ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	if s.creates.Add(1) == 1 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.Fake.CreateMultipartUpload(ctx, in, opts...)
+}
+
+func (s *stallingAPI) CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, opts ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if s.completes.Add(1) == 1 {
+		return &s3.CompleteMultipartUploadOutput{}, nil
+	}
+	return s.Fake.CompleteMultipartUpload(ctx, in, opts...)
+}
+
+func TestUpload_RequestTimeout(t *testing.T) {
+	api := &stallingAPI{Fake: streamuptest.NewFake()}
+	client, err := NewClient(ClientConfig{API: api})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	data := testData(6 * 1024 * 1024)
+	uploader, err := client.NewUploader(Config{
+		Bucket:         "test-bucket",
+		Key:            "test-key",
+		FileSize:       int64(len(data)),
+		RetryDelay:     1,
+		RequestTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewUploader() error = %v", err)
+	}
+	if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if api.creates.Load() != 2 || api.completes.Load() != 2 {
+		t.Errorf("create and complete calls = %d and %d, want 2 and 2", api.creates.Load(), api.completes.Load())
+	}
+	if uploader.etag == "" {
+		t.Error("ETag should come from the retried completion")
+	}
+}
+
+func TestUpload_FakeAbortRetries(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	fake.AddFault(streamuptest.Fault{Operation: streamuptest.OpAbortMultipartUpload, Times: 1, Err: streamuptest.ErrInternal()})
+	data := testData(6 * 1024 * 1024)
+
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+	err := uploader.Upload(bytes.NewReader(data))
+	var orphaned *OrphanedUploadError
+	if err == nil || errors.As(err, &orphaned) {
+		t.Fatalf("Upload() error = %v, want the completion error", err)
+	}
+	if ids := fake.Uploads(); len(ids) != 0 {
+		t.Errorf("uploads %v were not aborted", ids)
+	}
+	if got := fake.Calls(streamuptest.OpAbortMultipartUpload); got != 2 {
+		t.Errorf("AbortMultipartUpload calls = %d, want 2", got)
+	}
+}
+
+func TestUpload_FakeOrphanedUpload(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	fake.AddFault(streamuptest.Fault{Operation: streamuptest.OpAbortMultipartUpload, Err: streamuptest.ErrInternal()})
+	data := testData(6 * 1024 * 1024)
+
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data))})
+	err := uploader.Upload(bytes.NewReader(data))
+
+	var orphaned *OrphanedUploadError
+	if !errors.As(err, &orphaned) {
+		t.Fatalf("Upload() error = %v, want OrphanedUploadError", err)
+	}
+	ids := fake.Uploads()
+	if len(ids) != 1 || orphaned.UploadID != ids[0] {
+		t.Errorf("UploadID = %q, want the remaining upload %v", orphaned.UploadID, ids)
+	}
+	var uploadErr *UploadError
+	if !errors.As(err, &uploadErr) || uploadErr.Operation != "CompleteMultipartUpload" {
+		t.Errorf("Upload() error = %v, want it to wrap the completion error", err)
+	}
+}
+
+func TestUpload_FakeNoOverwrite(t *testing.T) {
+	fake := streamuptest.NewFake()
+	data := testData(1024)
+
+	first := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), IfNoneMatch: true})
+	if err := first.Upload(bytes.NewReader(data)); err != nil {
+		t.Fatalf("first Upload() error = %v", err)
+	}
+
+	second := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), IfNoneMatch: true})
+	err := second.Upload(bytes.NewReader(data))
+	var pfErr *PreconditionFailedError
+	if !errors.As(err, &pfErr) {
+		t.Errorf("second Upload() error = %v, want PreconditionFailedError", err)
+	}
+}
+
+// existsAfterCompleteAPI is a fake S3 that rejects every completion after the
+// first with 412 Precondition Failed, as S3 does for an IfNoneMatch upload
+// once the object exists.
+type existsAfterCompleteAPI struct {
+	*streamuptest.Fake
+	completes atomic.Int32
+}
+
+func (e *existsAfterCompleteAPI) CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, opts ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if e.completes.Add(1) > 1 {
+		return nil, streamuptest.APIError(http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+	}
+	return e.Fake.CompleteMultipartUpload(ctx, in, opts...)
+}
+
+func TestUpload_FakeLostCompleteResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		api         func(f *streamuptest.Fake) S3API
+		ifNoneMatch bool
+	}{
+		{"upload gone", func(f *streamuptest.Fake) S3API { return f }, false},
+		{"object exists", func(f *streamuptest.Fake) S3API { return &existsAfterCompleteAPI{Fake: f} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			fake.LoseCompleteResponse(1)
+			data := testData(6 * 1024 * 1024)
+
+			uploader := newFakeUploader(t, tt.api(fake), Config{
+				FileSize:    int64(len(data)),
+				IfNoneMatch: tt.ifNoneMatch,
+			})
+			if err := uploader.Upload(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			obj, ok := fake.Object("test-bucket", "test-key")
+			if !ok || !bytes.Equal(obj.Data, data) {
+				t.Fatal("stored data does not match the source")
+			}
+			if uploader.etag != obj.ETag {
+				t.Errorf("ETag = %q, want %q", uploader.etag, obj.ETag)
+			}
+			if got := fake.Calls(streamuptest.OpAbortMultipartUpload); got != 0 {
+				t.Errorf("AbortMultipartUpload calls = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestUpload_FakeCompleteConflictAfterRetry(t *testing.T) {
+	fake := streamuptest.NewFake()
+	existing := newFakeUploader(t, fake, Config{FileSize: 1024})
+	if err := existing.Upload(bytes.NewReader(testData(1024))); err != nil {
+		t.Fatalf("first Upload() error = %v", err)
+	}
+
+	// The retry's 412 is about another object, so it still fails
+	fake.FailComplete(1)
+	data := testData(6 * 1024 * 1024)
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), IfNoneMatch: true})
+	err := uploader.Upload(bytes.NewReader(data))
+
+	var pfErr *PreconditionFailedError
+	if !errors.As(err, &pfErr) {
+		t.Fatalf("Upload() error = %v, want PreconditionFailedError", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "test-key"); len(obj.Data) != 1024 {
+		t.Error("the existing object should be left alone")
+	}
+}
+
+func TestUpload_FakeCompleteConflictSameSize(t *testing.T) {
+	fake := streamuptest.NewFake()
+	other := bytes.Repeat([]byte{0xff}, 6*1024*1024)
+	existing := newFakeUploader(t, fake, Config{FileSize: int64(len(other))})
+	if err := existing.Upload(bytes.NewReader(other)); err != nil {
+		t.Fatalf("first Upload() error = %v", err)
+	}
+
+	// Same size and number of parts, but not this upload's ETag
+	fake.FailComplete(1)
+	data := testData(len(other))
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), IfNoneMatch: true})
+	err := uploader.Upload(bytes.NewReader(data))
+
+	var pfErr *PreconditionFailedError
+	if !errors.As(err, &pfErr) {
+		t.Fatalf("Upload() error = %v, want PreconditionFailedError", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "test-key"); !bytes.Equal(obj.Data, other) {
+		t.Error("the existing object should be left alone")
+	}
+}
+
+func TestUpload_FakeLostCompleteResponseSSEKMS(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.LoseCompleteResponse(1)
+	data := testData(6 * 1024 * 1024)
+
+	// SSE-KMS ETags aren't MD5 digests, so the stored object can't be matched
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), ServerSideEncryption: SSEKMS})
+	if err := uploader.Upload(bytes.NewReader(data)); err == nil {
+		t.Fatal("Upload() should fail when the completion can't be confirmed")
+	}
+}
+
+func TestUpload_FakeAbortIgnoresRetryBudget(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	fake.AddFault(streamuptest.Fault{Operation: streamuptest.OpAbortMultipartUpload, Times: 1, Err: streamuptest.ErrInternal()})
+	data := testData(6 * 1024 * 1024)
+
+	// Completing uses up the budget, but aborting is still retried
+	uploader := newFakeUploader(t, fake, Config{FileSize: int64(len(data)), RetryBudget: 1})
+	err := uploader.Upload(bytes.NewReader(data))
+
+	var budgetErr *RetryBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Upload() error = %v, want RetryBudgetError", err)
+	}
+	var orphaned *OrphanedUploadError
+	if errors.As(err, &orphaned) {
+		t.Fatalf("Upload() error = %v, want the upload aborted", err)
+	}
+	if ids := fake.Uploads(); len(ids) != 0 {
+		t.Errorf("uploads %v were not aborted", ids)
+	}
+}
+
+// stallingAbortAPI is a fake S3 whose AbortMultipartUpload never answers.
+type stallingAbortAPI struct {
+	*streamuptest.Fake
+}
+
+func (s *stallingAbortAPI) AbortMultipartUpload(ctx context.Context, _ *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestUpload_AbortTimeout(t *testing.T) {
+	fake := streamuptest.NewFake()
+	fake.FailComplete(0)
+	data := testData(6 * 1024 * 1024)
+
+	uploader := newFakeUploader(t, &stallingAbortAPI{Fake: fake}, Config{
+		FileSize:     int64(len(data)),
+		MaxRetries:   100,
+		AbortTimeout: 100 * time.Millisecond,
+	})
+	started := time.Now()
+	err := uploader.Upload(bytes.NewReader(data))
+
+	var orphaned *OrphanedUploadError
+	if !errors.As(err, &orphaned) {
+		t.Fatalf("Upload() error = %v, want OrphanedUploadError", err)
+	}
+	var timeoutErr *TimeoutError
+	if !errors.As(orphaned.AbortErr, &timeoutErr) || timeoutErr.Operation != "AbortMultipartUpload" {
+		t.Errorf("AbortErr = %v, want a TimeoutError", orphaned.AbortErr)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Upload() took %s, want the abort cut off after AbortTimeout", elapsed)
+	}
+}
+
+func TestUpload_FakeEmptyStreamMultipart(t *testing.T) {
+	fake := streamuptest.NewFake()
+	uploader := newFakeUploader(t, fake, Config{FileSize: UnknownSize, SinglePartThreshold: -1})
+	if err := uploader.Upload(struct{ io.Reader }{bytes.NewReader(nil)}); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	// A multipart upload needs at least one part, even an empty one
+	obj, ok := fake.Object("test-bucket", "test-key")
+	if !ok || len(obj.Data) != 0 || obj.Parts != 1 {
+		t.Errorf("Object() = %+v, %v, want an empty object of one part", obj, ok)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// VerificationResult compares what was uploaded with what the service stored.
+type VerificationResult struct {
+	ExpectedSize int64  // Bytes read from the source
+	ActualSize   int64  // ContentLength reported by HeadObject
+	ExpectedETag string // Locally computed ETag (empty if it could not be derived)
+	ActualETag   string // ETag reported by HeadObject
+}
+
+// SizeMatches reports whether the stored object has the expected length.
+func (r VerificationResult) SizeMatches() bool {
+	return r.ExpectedSize == r.ActualSize
+}
+
+// ETagMatches reports whether the stored ETag matches the local calculation.
+// It is true when no expected ETag could be derived (e.g. resumed parts with
+// non-MD5 ETags), since there is nothing to compare against.
+func (r VerificationResult) ETagMatches() bool {
+	return r.ExpectedETag == "" || r.ExpectedETag == r.ActualETag
+}
+
+// multipartETag computes the ETag S3 assigns to a multipart upload: the MD5
+// of the concatenated binary part MD5s, followed by "-<number of parts>".
+// It returns an empty string if any part digest is missing.
+func multipartETag(digests map[int32][]byte) string {
+	numbers := make([]int32, 0, len(digests))
+	for n, d := range digests {
+		if d == nil {
+			return ""
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	h := md5.New()
+	for _, n := range numbers {
+		h.Write(digests[n])
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(numbers))
+}
+
+// etagDigest decodes a plain (single-part) ETag into its MD5 digest,
+// returning nil if the ETag is not an MD5 hex string.
+func etagDigest(etag string) []byte {
+	digest, err := hex.DecodeString(normalizeETag(etag))
+	if err != nil || len(digest) != md5.Size {
+		return nil
+	}
+	return digest
+}
+
+// verifyUpload fetches the stored object's metadata and compares it with
+// what was produced locally.
+func (u *Uploader) verifyUpload(expectedSize int64, expectedETag string) error {
+	var resp *s3.HeadObjectOutput
+	err := u.withRetry("HeadObject", func() error {
+		var err error
+		resp, err = u.s3Client.HeadObject(u.ctx, &s3.HeadObjectInput{
+			Bucket:               aws.String(u.config.Bucket),
+			Key:                  aws.String(u.config.Key),
+			SSECustomerAlgorithm: u.sse.customerAlgorithm,
+			SSECustomerKey:       u.sse.customerKey,
+			SSECustomerKeyMD5:    u.sse.customerKeyMD5,
+		})
+		return err
+	})
+	if err != nil {
+		return &UploadError{Operation: "HeadObject", Err: err}
+	}
+
+	result := &VerificationResult{
+		ExpectedSize: expectedSize,
+		ActualSize:   aws.ToInt64(resp.ContentLength),
+		ExpectedETag: expectedETag,
+		ActualETag:   normalizeETag(aws.ToString(resp.ETag)),
+	}
+	u.verification = result
+
+	if !result.SizeMatches() || !result.ETagMatches() {
+		return &VerificationError{Result: *result}
+	}
+	return nil
+}
+
+// GetVerification returns the result of post-upload verification.
+// Returns nil if Config.VerifyUpload was not set or the upload did not complete.
+func (u *Uploader) GetVerification() *VerificationResult {
+	return u.verification
+}
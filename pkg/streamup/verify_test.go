@@ -0,0 +1,144 @@
+// Copyright 2025 Matthew Gall <me@matthewgall.dev>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamup
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/matthewgall/streamup/pkg/streamup/streamuptest"
+)
+
+func TestMultipartETag(t *testing.T) {
+	part1 := md5.Sum([]byte("first part"))
+	part2 := md5.Sum([]byte("second part"))
+
+	// S3 hashes the concatenated binary digests in part order
+	combined := md5.Sum(append(append([]byte{}, part1[:]...), part2[:]...))
+	want := hex.EncodeToString(combined[:]) + "-2"
+
+	got := multipartETag(map[int32][]byte{2: part2[:], 1: part1[:]})
+	if got != want {
+		t.Errorf("multipartETag() = %q, want %q", got, want)
+	}
+
+	// A part without a digest makes the ETag unknowable
+	if got := multipartETag(map[int32][]byte{1: part1[:], 2: nil}); got != "" {
+		t.Errorf("multipartETag() with missing digest = %q, want empty", got)
+	}
+}
+
+func TestEtagDigest(t *testing.T) {
+	sum := md5.Sum([]byte("data"))
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	if got := etagDigest(etag); string(got) != string(sum[:]) {
+		t.Errorf("etagDigest(%q) = %x, want %x", etag, got, sum)
+	}
+	if got := etagDigest("not-an-md5"); got != nil {
+		t.Errorf("etagDigest() of non-MD5 ETag = %x, want nil", got)
+	}
+}
+
+func TestVerificationError(t *testing.T) {
+	sizeErr := &VerificationError{Result: VerificationResult{ExpectedSize: 10, ActualSize: 8}}
+	if !strings.Contains(sizeErr.Error(), "size is 8 bytes, expected 10") {
+		t.Errorf("Error() = %q, want size mismatch", sizeErr.Error())
+	}
+
+	etagErr := &VerificationError{Result: VerificationResult{
+		ExpectedSize: 10,
+		ActualSize:   10,
+		ExpectedETag: "abc-2",
+		ActualETag:   "def-2",
+	}}
+	if !strings.Contains(etagErr.Error(), "ETag is def-2, expected abc-2") {
+		t.Errorf("Error() = %q, want ETag mismatch", etagErr.Error())
+	}
+
+	// Without an expected ETag only the size is compared
+	if !(VerificationResult{ActualETag: "def-2"}).ETagMatches() {
+		t.Error("ETagMatches() = false with no expected ETag, want true")
+	}
+}
+
+// corruptingAPI reports a different stored object from HeadObject than the
+// one that was uploaded.
+type corruptingAPI struct {
+	*streamuptest.Fake
+	head func(out *s3.HeadObjectOutput)
+}
+
+func (c *corruptingAPI) HeadObject(ctx context.Context, in *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	out, err := c.Fake.HeadObject(ctx, in, opts...)
+	if err == nil {
+		c.head(out)
+	}
+	return out, err
+}
+
+func TestUpload_VerificationMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		head func(out *s3.HeadObjectOutput)
+		want string
+	}{
+		{"multipart ETag", 12 * 1024 * 1024, func(out *s3.HeadObjectOutput) {
+			out.ETag = aws.String(`"0123456789abcdef0123456789abcdef-2"`)
+		}, "ETag"},
+		{"multipart size", 12 * 1024 * 1024, func(out *s3.HeadObjectOutput) {
+			out.ContentLength = aws.Int64(5 * 1024 * 1024)
+		}, "size"},
+		{"single part ETag", 1024, func(out *s3.HeadObjectOutput) {
+			out.ETag = aws.String(`"0123456789abcdef0123456789abcdef"`)
+		}, "ETag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := streamuptest.NewFake()
+			fake.MinPartSize = defaultMinPartSize
+			data := testData(tt.size)
+
+			uploader := newFakeUploader(t, &corruptingAPI{Fake: fake, head: tt.head}, Config{
+				FileSize:     int64(len(data)),
+				VerifyUpload: true,
+			})
+			err := uploader.Upload(bytes.NewReader(data))
+
+			var verifyErr *VerificationError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("Upload() error = %v, want VerificationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Upload() error = %v, want a %s mismatch", err, tt.want)
+			}
+			if v := uploader.GetVerification(); v == nil || v.SizeMatches() && v.ETagMatches() {
+				t.Errorf("GetVerification() = %+v, want a mismatch", v)
+			}
+			if _, ok := fake.Object("test-bucket", "test-key"); !ok {
+				t.Error("a completed upload is reported, not deleted")
+			}
+		})
+	}
+}